@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"slices"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/dkotik/pageseo/internal"
@@ -12,32 +13,155 @@ import (
 )
 
 type anchor struct {
-	Normalizer    Normalizer
-	MinimumLength int
-	MaximumLength int
-	Cache         *cachedParsedURLs
+	Normalizer     Normalizer
+	MinimumLength  int
+	MaximumLength  int
+	GenericPhrases map[string][]string
+	Cache          *cachedParsedURLs
+
+	// Origins maps each document node under test to its
+	// page location for resolving links in the cleanup.
+	Origins *sync.Map
+}
+
+// AnchorNodeConstraints configure the anchor [NodeTester].
+type AnchorNodeConstraints struct {
+	Text StringConstraints
+
+	// GenericPhrases lists non-descriptive anchor texts
+	// by the base language subtag of the page [lang]
+	// attribute. Defaults to [DefaultGenericLinkPhrases].
+	GenericPhrases map[string][]string
 }
 
 func NewAnchorNodeTester(s StringConstraints) NodeTester {
-	if s.Normalizer == nil {
-		s.Normalizer = NormalizeLineToNFC
+	return NewAnchorNodeTesterWithConstraints(AnchorNodeConstraints{Text: s})
+}
+
+// NewAnchorNodeTesterWithConstraints also customizes
+// the list of generic link phrases.
+func NewAnchorNodeTesterWithConstraints(constraints AnchorNodeConstraints) NodeTester {
+	if constraints.Text.Normalizer == nil {
+		constraints.Text.Normalizer = NormalizeLineToNFC
+	}
+	if constraints.Text.MinimumLength < 1 {
+		constraints.Text.MinimumLength = DefaultMinimumAnchorTextLength
 	}
-	if s.MinimumLength < 1 {
-		s.MinimumLength = DefaultMinimumAnchorTextLength
+	if constraints.Text.MaximumLength < 1 {
+		constraints.Text.MaximumLength = DefaultMaximumAnchorTextLength
 	}
-	if s.MaximumLength < 1 {
-		s.MaximumLength = DefaultMaximumAnchorTextLength
+	if constraints.GenericPhrases == nil {
+		constraints.GenericPhrases = DefaultGenericLinkPhrases
 	}
 	return anchor{
-		Normalizer:    s.Normalizer,
-		MinimumLength: s.MinimumLength,
-		MaximumLength: s.MaximumLength,
-		Cache:         &cachedParsedURLs{},
+		Normalizer:     constraints.Text.Normalizer,
+		MinimumLength:  constraints.Text.MinimumLength,
+		MaximumLength:  constraints.Text.MaximumLength,
+		GenericPhrases: constraints.GenericPhrases,
+		Cache:          &cachedParsedURLs{},
+		Origins:        &sync.Map{},
 	}
 }
 
 func (a anchor) Match(t testing.TB, node *html.Node) bool {
-	return node.Type == html.ElementNode && node.Data == "a"
+	switch node.Type {
+	case html.ElementNode:
+		return node.Data == "a"
+	case html.DocumentNode:
+		t.Cleanup(func() {
+			origin, ok := a.Origins.LoadAndDelete(node)
+			if !ok {
+				return // no anchors were tested
+			}
+			validateAnchorTextTargets(t, origin.(*url.URL), node)
+		})
+		return false
+	default:
+		return false
+	}
+}
+
+// validateAnchorTextTargets reports identical anchor texts that
+// lead to different locations, which makes them ambiguous.
+func validateAnchorTextTargets(t testing.TB, origin *url.URL, document *html.Node) {
+	targets := make(map[string]string)
+	reported := make(map[[2]string]bool)
+	for node := range document.Descendants() {
+		if node.Type != html.ElementNode || node.Data != "a" {
+			continue
+		}
+		href, ok := internal.GetAttribute(node, "href")
+		if !ok {
+			continue
+		}
+		href = resolveAnchorTarget(origin, href)
+		text := normalizeLinkPhrase(getAnchorName(node))
+		if href == "" || text == "" {
+			continue
+		}
+		first, ok := targets[text]
+		if !ok {
+			targets[text] = href
+			continue
+		}
+		if key := [2]string{text, href}; first != href && !reported[key] {
+			reported[key] = true
+			t.Errorf("anchor text %q leads to different locations: %q and %q", text, first, href)
+		}
+	}
+}
+
+// resolveAnchorTarget returns the absolute location of a link
+// without the fragment and the trailing slash for comparison.
+// Returns an empty string for links within the same page.
+func resolveAnchorTarget(origin *url.URL, href string) string {
+	href, _, _ = strings.Cut(strings.TrimSpace(href), "#")
+	if href == "" {
+		return ""
+	}
+	href, query, hasQuery := strings.Cut(href, "?")
+	location, err := url.Parse(joinRelativePath(origin, href))
+	if err != nil {
+		return href
+	}
+	location.Host = strings.ToLower(location.Host)
+	if location.Path == "." || location.Path == "/." {
+		location.Path = "" // cleaned empty path of an external link
+	}
+	location.Path = strings.TrimSuffix(location.Path, "/")
+	location.RawPath = ""
+	location.RawQuery, location.ForceQuery = query, hasQuery && query == ""
+	location.Fragment, location.RawFragment = "", ""
+	return location.String()
+}
+
+// getAnchorName returns the anchor text or, for links
+// that only contain icons, the name given by attributes.
+func getAnchorName(node *html.Node) string {
+	if text := internal.GetAndTrimText(node); text != "" {
+		return text
+	}
+	for _, key := range [...]string{"aria-label", "title"} {
+		if value, ok := internal.GetAttribute(node, key); ok && strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	for descendant := range node.Descendants() {
+		if descendant.Type != html.ElementNode {
+			continue
+		}
+		switch descendant.Data {
+		case "img":
+			if alt, ok := internal.GetAttribute(descendant, "alt"); ok && strings.TrimSpace(alt) != "" {
+				return strings.TrimSpace(alt)
+			}
+		case "svg":
+			if label, ok := internal.GetAttribute(descendant, "aria-label"); ok && strings.TrimSpace(label) != "" {
+				return strings.TrimSpace(label)
+			}
+		}
+	}
+	return ""
 }
 
 func (a anchor) ListResourcesForPreloading(origin *url.URL, node *html.Node) (URLs []string) {
@@ -45,7 +169,11 @@ func (a anchor) ListResourcesForPreloading(origin *url.URL, node *html.Node) (UR
 		if attr.Key == "href" {
 			url, _, _ := strings.Cut(attr.Val, "#")
 			if url != "" {
-				URLs = append(URLs, joinRelativePath(origin, url))
+				url = joinRelativePath(origin, url)
+				URLs = append(URLs, url)
+				if secure, ok := getSecureAlternative(url); ok {
+					URLs = append(URLs, secure)
+				}
 			}
 			return URLs // only take the first href attribute
 		}
@@ -54,6 +182,13 @@ func (a anchor) ListResourcesForPreloading(origin *url.URL, node *html.Node) (UR
 }
 
 func (a anchor) TestNode(t testing.TB, origin *url.URL, node *html.Node, loader Loader) {
+	if a.Origins != nil {
+		document := node
+		for document.Parent != nil {
+			document = document.Parent
+		}
+		a.Origins.LoadOrStore(document, origin)
+	}
 	attributes := internal.GetAttributes(t, node)
 	rel := []string{}
 	relString, ok := attributes["rel"]
@@ -97,18 +232,24 @@ func (a anchor) TestNode(t testing.TB, origin *url.URL, node *html.Node, loader
 	}
 
 	href, ok := attributes["href"]
+	href, fragment, hasFragment := strings.Cut(href, "#")
 	if !ok {
 		if _, ok = attributes["onclick"]; !ok {
 			if _, ok = attributes["id"]; !ok { // <a id="#hash" />
 				t.Error("add <a[href]> link attribute")
 			}
 		}
-	} else if href, _, _ = strings.Cut(href, "#"); href != "" {
+	} else if href == "" {
+		if hasFragment && !hasFragmentTarget(getDocumentRoot(node), fragment) {
+			t.Errorf("link fragment #%s does not match any [id] or <a[name]> on <%s>", fragment, origin)
+		}
+	} else {
+		isExternal := false
 		url, err := a.Cache.Get(href)
 		if err != nil {
 			t.Logf("%s failed to parse location: %v", internal.WP, err)
 		} else {
-			if IsExternalLocation(origin, url) {
+			if isExternal = IsExternalLocation(origin, url); isExternal {
 				if !IsSubdomainOfOrigin(origin, url) {
 					if slices.Index(rel, "external") == -1 {
 						t.Log(internal.WP, "add \"external\" directive to [rel] attribute")
@@ -123,35 +264,19 @@ func (a anchor) TestNode(t testing.TB, origin *url.URL, node *html.Node, loader
 			href = url.String()
 		}
 
-		target, contentType, err := loader.Load(t.Context(), href)
-		if err != nil {
-			if errors.Is(err, Skip) {
-				return
-			}
-			t.Errorf("unable to load anchor %q: %v", href, err)
-		}
-
-		switch contentType {
-		case "":
-			t.Error("empty Content-Type for the link <a[href]> target")
-		case "text/html":
-		case "text/markdown":
-		case "application/pdf":
-		case "text/plain":
-		case "image/jpeg":
-		case "image/png":
-		case "image/webp":
-		case "image/gif":
-		case "image/svg+xml":
-		case "image/avif":
-		case "audio/mpeg":
-		case "video/mp4":
-		default:
-			t.Log("strange link <a[href]> target Content-Type:", contentType)
+		if err = RecordPageLink(t.Context(), loader, PageLink{
+			Source: origin.String(),
+			Target: href,
+			Text:   getAnchorName(node),
+			Rel:    rel,
+		}); err != nil {
+			t.Logf("%s unable to record page link: %v", internal.WP, err)
 		}
-		if len(target) == 0 {
-			t.Error("empty <a[href]> target file")
+		if isExternal {
+			fragment = "" // other sites are not checked
 		}
+		validateSecureAlternative(t, href, loader)
+		a.validateTarget(t, origin, href, fragment, !isExternal, loader)
 	}
 
 	isEmpty := true
@@ -169,19 +294,98 @@ func (a anchor) TestNode(t testing.TB, origin *url.URL, node *html.Node, loader
 
 	if isEmpty {
 		t.Error("anchor is empty of meaningful content")
-	} else {
-		text := internal.GetAndTrimText(node)
-		normalized, err := a.Normalizer.Normalize(text)
-		if err != nil {
-			t.Logf(internal.WP+" unable to normalize anchor text: %v", err)
-		} else if normalized != text {
-			t.Log(internal.WP + " anchor text is not normalized")
+		return
+	}
+	text := internal.GetAndTrimText(node)
+	if text == "" {
+		text = getAnchorName(node)
+		if text == "" {
+			t.Error("icon-only link has no [aria-label] or [title] attribute")
+			return
 		}
-		length := len(text)
-		if length < a.MinimumLength && href != "" {
-			t.Error("anchor text is too short")
-		} else if length > a.MaximumLength {
-			t.Error("anchor text is too long")
+	}
+	normalized, err := a.Normalizer.Normalize(text)
+	if err != nil {
+		t.Logf(internal.WP+" unable to normalize anchor text: %v", err)
+	} else if normalized != text {
+		t.Log(internal.WP + " anchor text is not normalized")
+	}
+	length := len(text)
+	if length < a.MinimumLength && href != "" {
+		t.Error("anchor text is too short")
+	} else if length > a.MaximumLength {
+		t.Error("anchor text is too long")
+	}
+
+	if isGenericLinkPhrase(a.GenericPhrases, getPageLanguage(node), text) {
+		t.Errorf("anchor text %q does not describe the link destination", text)
+	} else if isBareURL(text) {
+		t.Log(internal.WP, "anchor text is a bare URL, describe the link destination instead:", text)
+	}
+}
+
+// validateTarget loads the link destination. Fragment, if
+// not empty, must match an element of the HTML destination.
+func (a anchor) validateTarget(t testing.TB, origin *url.URL, href, fragment string, isInternal bool, loader Loader) {
+	response, err := LoadResponse(t.Context(), loader, href)
+	if errors.Is(err, ErrRedirectLoop) {
+		var chain []Redirect
+		if response != nil {
+			chain = response.Redirects
+		}
+		validateRedirects(t, href, isInternal, chain, err)
+		return
+	}
+	if err != nil {
+		if errors.Is(err, Skip) {
+			return
+		}
+		t.Errorf("unable to load anchor %q: %v", href, err)
+	}
+	if response == nil {
+		return
+	}
+	target, contentType := response.Content, response.ContentType
+	if response.Attempts > 1 {
+		t.Logf("%s link target %q loaded after %d attempts", internal.WP, href, response.Attempts)
+	}
+	validateRedirects(t, href, isInternal, response.Redirects, nil)
+	if isInternal {
+		validateCompression(t, href, response)
+	}
+	if isInternal && isExcludedFromIndex(response.Header) {
+		t.Logf("%s internal link %q points to a page excluded from search results by <X-Robots-Tag>", internal.WP, href)
+	}
+
+	switch contentType {
+	case "":
+		t.Error("empty Content-Type for the link <a[href]> target")
+	case "text/html":
+	case "text/markdown":
+	case "application/pdf":
+	case "text/plain":
+	case "image/jpeg":
+	case "image/png":
+	case "image/webp":
+	case "image/gif":
+	case "image/svg+xml":
+	case "image/avif":
+	case "audio/mpeg":
+	case "video/mp4":
+	default:
+		t.Log("strange link <a[href]> target Content-Type:", contentType)
+	}
+	if len(target) == 0 && !response.Truncated {
+		t.Error("empty <a[href]> target file")
+		return
+	}
+
+	if fragment != "" && contentType == "text/html" && !response.Truncated {
+		found, err := hasFragmentTargetInHTML(target, fragment)
+		if err != nil {
+			t.Logf("%s unable to parse <a[href]> target %q: %v", internal.WP, href, err)
+		} else if !found {
+			t.Errorf("link fragment #%s on <%s> does not match any [id] or <a[name]> on <%s>", fragment, origin, href)
 		}
 	}
 }
@@ -6,9 +6,8 @@ import (
 )
 
 type cachedResource struct {
-	Content     []byte
-	ContentType string
-	Error       error
+	Response *Response
+	Error    error
 }
 
 type cachedLoader struct {
@@ -19,6 +18,9 @@ type cachedLoader struct {
 	cache map[string]cachedResource
 }
 
+// NewCache keeps every loaded resource in memory. OnGrow,
+// if not nil, is called for every newly cached resource.
+// Use [NewMemoryCache] to limit memory usage.
 func NewCache(onGrow func(Resource)) Middleware {
 	cache := make(map[string]cachedResource, 64)
 	mu := &sync.Mutex{}
@@ -32,29 +34,47 @@ func NewCache(onGrow func(Resource)) Middleware {
 	})
 }
 
+func (cl cachedLoader) TraceRedirects(URL string) []Redirect {
+	return TraceRedirects(cl.Loader, URL)
+}
+
+func (cl cachedLoader) RecordPageLink(ctx context.Context, link PageLink) error {
+	return RecordPageLink(ctx, cl.Loader, link)
+}
+
 func (cl cachedLoader) Load(ctx context.Context, url string) ([]byte, string, error) {
+	response, err := cl.LoadResponse(ctx, url)
+	if response == nil {
+		return nil, "", err
+	}
+	return response.Content, response.ContentType, err
+}
+
+func (cl cachedLoader) LoadResponse(ctx context.Context, url string) (*Response, error) {
 	cl.mu.Lock()
 	cached, ok := cl.cache[url]
 	cl.mu.Unlock()
 	if ok {
-		return cached.Content, cached.ContentType, cached.Error
+		return fromCache(cached.Response), cached.Error
+	}
+	fresh, err := LoadResponse(ctx, cl.Loader, url)
+	if fresh == nil {
+		fresh = &Response{URL: url, FinalURL: url}
 	}
-	fresh, freshContentType, err := cl.Loader.Load(ctx, url)
 	cl.mu.Lock()
 	_, ok = cl.cache[url] // check if resource maybe loaded in parallel
 	cl.cache[url] = cachedResource{
-		Content:     fresh,
-		ContentType: freshContentType,
-		Error:       err,
+		Response: fresh,
+		Error:    err,
 	}
 	cl.mu.Unlock()
-	if !ok { // true unique
+	if !ok && cl.OnGrow != nil { // true unique
 		cl.OnGrow(Resource{
 			URL:         url,
-			Content:     fresh,
-			ContentType: freshContentType,
+			Content:     fresh.Content,
+			ContentType: fresh.ContentType,
 			Error:       err,
 		})
 	}
-	return fresh, freshContentType, err
+	return fresh, err
 }
@@ -15,6 +15,9 @@ func init() {
 	// running [testing.MainStart]
 	_ = flag.Uint(flagLimit.Name, flagLimit.Value, flagLimit.Usage)
 	_ = flag.Bool(flagFailFast.Name, false, flagFailFast.Usage)
+	for _, f := range []*cli.StringFlag{flagCache, flagDiskCache, flagWARC, flagReplay, flagMetricsAddress} {
+		_ = flag.String(f.Name, f.Value, f.Usage)
+	}
 	// _ = flag.Bool(flagStrict.Name, false, flagStrict.Usage)
 	_ = flag.Bool(flagShort.Name, false, flagShort.Usage)
 	_ = flag.Bool(flagVerbose.Name, false, flagVerbose.Usage)
@@ -50,6 +53,36 @@ var (
 		Value:   ":memory:",
 	}
 
+	flagDiskCache = &cli.StringFlag{
+		Name:  "disk-cache",
+		Usage: "directory for keeping downloaded resources between runs",
+	}
+
+	flagWARC = &cli.StringFlag{
+		Name:  "warc",
+		Usage: "archive crawled resources into a WARC file, compressed if the name ends with .gz",
+	}
+
+	flagReplay = &cli.StringFlag{
+		Name:  "replay",
+		Usage: "audit resources archived in a WARC file instead of loading them",
+	}
+
+	flagMetricsAddress = &cli.StringFlag{
+		Name:  "metrics-address",
+		Usage: "serve loader metrics in Prometheus format on /metrics and as JSON on /debug/vars, like :9090",
+	}
+
+	flagMaximumAge = &cli.DurationFlag{
+		Name:  "max-age",
+		Usage: "remove cached resources downloaded longer ago",
+	}
+
+	flagMaximumSize = &cli.UintFlag{
+		Name:  "max-megabytes",
+		Usage: "remove the oldest cached resources until the cache fits",
+	}
+
 	flagFailFast = &cli.BoolFlag{
 		Name:  "failfast",
 		Usage: "end the test on the first detected failure",
@@ -3,8 +3,11 @@ package main
 import (
 	"context"
 	"errors"
+	"expvar"
 	"fmt"
 	"io/fs"
+	"net"
+	"net/http"
 	"net/url"
 	"os"
 	"runtime/debug"
@@ -14,8 +17,14 @@ import (
 
 	"github.com/dkotik/pageseo"
 	"github.com/dkotik/pageseo/crawler"
+	"github.com/dkotik/pageseo/crawler/linkgraph"
 	"github.com/dkotik/pageseo/crawler/repository"
+	"github.com/dkotik/pageseo/diskcache"
 	"github.com/dkotik/pageseo/internal"
+	"github.com/dkotik/pageseo/metrics"
+	"github.com/dkotik/pageseo/robots"
+	"github.com/dkotik/pageseo/sitemap"
+	"github.com/dkotik/pageseo/warc"
 	"github.com/urfave/cli/v3"
 	"mvdan.cc/xurls/v2"
 	"zombiezen.com/go/sqlite"
@@ -47,9 +56,25 @@ func main() {
 			flagLimit,
 			// flagStrict,
 			flagCache,
+			flagDiskCache,
+			flagWARC,
+			flagReplay,
+			flagMetricsAddress,
 			flagFailFast,
 			flagVerbose,
 		},
+		Commands: []*cli.Command{
+			{
+				Name:  "prune",
+				Usage: "remove old resources from the disk cache",
+				Flags: []cli.Flag{
+					flagDiskCache,
+					flagMaximumAge,
+					flagMaximumSize,
+				},
+				Action: cli.ActionFunc(prune),
+			},
+		},
 		Action: cli.ActionFunc(func(ctx context.Context, cmd *cli.Command) (err error) {
 			limit := cmd.Uint(flagLimit.Name)
 			targets := cmd.Args()
@@ -60,7 +85,7 @@ func main() {
 
 			var v pageseo.PageTester
 			fsys := os.DirFS(".")
-			loader := pageseo.NewFS(fsys)
+			loader := pageseo.NewFSWithSizeLimit(fsys, pageseo.SizeLimitConstraints{})
 
 			v = pageseo.New(loader)
 			tests := make([]testing.InternalTest, 0, targets.Len())
@@ -109,12 +134,57 @@ func main() {
 					err = errors.Join(err, conn.Close())
 				}()
 
-				cr, err := crawler.New(
+				source := crawler.NewLoader()
+				crawlerOptions := []crawler.Option{
+					crawler.WithSQLiteConn(conn),
+					crawler.WithTimeToLive(time.Minute * 10),
+					crawler.WithRobotsTxt(crawler.UserAgent),
+				}
+				if archive := cmd.String(flagReplay.Name); archive != "" {
+					if source, err = warc.Open(archive, warc.FailOnMiss); err != nil {
+						return err
+					}
+					crawlerOptions = append(crawlerOptions, crawler.WithLoader(source))
+				} else {
+					crawlerOptions = append(crawlerOptions, crawler.WithDelay(time.Second, time.Second*5))
+				}
+				var writer *warc.Writer
+				if archive := cmd.String(flagWARC.Name); archive != "" {
+					if writer, err = warc.Create(archive); err != nil {
+						return err
+					}
+					source = writer.WrapLoader(source)
+					crawlerOptions = append(crawlerOptions, crawler.WithMiddleware(writer))
+				}
+				if directory := cmd.String(flagDiskCache.Name); directory != "" {
+					cache, err := diskcache.Open(directory, time.Hour*24)
+					if err != nil {
+						return err
+					}
+					crawlerOptions = append(crawlerOptions, crawler.WithMiddleware(cache))
+				}
+				// the middleware added later wraps the earlier one
+				collector := metrics.New()
+				source = collector.WrapLoader(source)
+				crawlerOptions = append(crawlerOptions, crawler.WithMiddleware(collector))
+				if address := cmd.String(flagMetricsAddress.Name); address != "" {
+					if err = serveMetrics(ctx, collector, address); err != nil {
+						return err
+					}
+				}
+
+				var cr crawler.Crawler
+				cr, err = crawler.New(
 					crawler.AnalyzerFunc(func(ctx context.Context, t repository.Target) error {
+						// the repository answers with the stored response
+						response, err := pageseo.LoadResponse(ctx, cr, t.Location)
+						if err != nil {
+							return err
+						}
 						runTests([]testing.InternalTest{
 							internal.NewTest(
 								t.Location,
-								v.TestPage(t.Location, t.Content),
+								v.TestResponse(response),
 							),
 						})
 						limit = limit - 1
@@ -123,16 +193,15 @@ func main() {
 						}
 						return nil
 					}),
-					crawler.WithSQLiteConn(conn),
-					crawler.WithDelay(time.Second, time.Second*5),
-					crawler.WithTimeToLive(time.Minute*10),
+					crawlerOptions...,
 				)
 				if err != nil {
 					return err
 				}
 
-				// override the tester with crawler as the loader
-				v = pageseo.New(cr)
+				// override the tester with crawler as the loader,
+				// counting the loads the repository answers
+				v = pageseo.New(collector.WrapLoader(cr))
 
 				for _, r := range remote {
 					if err = cr.CrawlLocation(ctx, r); err != nil {
@@ -140,6 +209,26 @@ func main() {
 							return err
 						}
 					}
+					runTests([]testing.InternalTest{
+						internal.NewTest(
+							"robots.txt of "+r,
+							func(t *testing.T) {
+								robots.Test(source, r)(t)
+							},
+						),
+					})
+					if err = testLinkGraph(ctx, cr, source, r); err != nil {
+						return err
+					}
+				}
+				if writer != nil {
+					if err = errors.Join(writer.Err(), writer.Close()); err != nil {
+						return fmt.Errorf("unable to archive the crawl: %w", err)
+					}
+				}
+				fmt.Println()
+				if err = collector.WriteSummary(os.Stdout); err != nil {
+					return err
 				}
 			}
 
@@ -159,6 +248,84 @@ func main() {
 	}
 }
 
+// testLinkGraph reports orphan sitemap pages, buried pages,
+// and pages with few inbound links discovered by the crawler.
+func testLinkGraph(ctx context.Context, cr crawler.Crawler, loader pageseo.Loader, location string) error {
+	graph, err := cr.LinkGraph(ctx, location)
+	if err != nil {
+		return fmt.Errorf("unable to assemble link graph: %w", err)
+	}
+	root, err := url.Parse(graph.Start)
+	if err != nil {
+		return err
+	}
+	// the sitemap is optional
+	siteMaps, _ := robots.SiteMaps(ctx, loader, graph.Start)
+	if len(siteMaps) == 0 {
+		siteMaps = []string{root.JoinPath("sitemap.xml").String()}
+	}
+	var locations []string
+	for _, siteMap := range siteMaps {
+		found, _ := sitemap.Locations(ctx, loader, siteMap)
+		locations = append(locations, found...)
+	}
+	runTests([]testing.InternalTest{
+		internal.NewTest(
+			"link graph of "+graph.Start,
+			linkgraph.Test(graph, linkgraph.Constraints{
+				SiteMapLocations: locations,
+			}),
+		),
+	})
+	return nil
+}
+
+// serveMetrics exposes the loader statistics in the Prometheus
+// format on "/metrics" and as JSON on "/debug/vars" until
+// the context is done.
+func serveMetrics(ctx context.Context, collector *metrics.Collector, address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("unable to serve metrics: %w", err)
+	}
+	collector.Publish("pageseo")
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", collector)
+	mux.Handle("/debug/vars", expvar.Handler())
+	server := &http.Server{Handler: mux}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	return nil
+}
+
+func prune(ctx context.Context, cmd *cli.Command) error {
+	directory := cmd.String(flagDiskCache.Name)
+	if directory == "" {
+		return errors.New("disk cache directory is required")
+	}
+	cache, err := diskcache.Open(directory, 0)
+	if err != nil {
+		return err
+	}
+	result, err := cache.Prune(
+		cmd.Duration(flagMaximumAge.Name),
+		int64(cmd.Uint(flagMaximumSize.Name))*1024*1024,
+	)
+	if err != nil {
+		return err
+	}
+	fmt.Printf(
+		" [🧹] Removed %d entries and %d files, freed %d bytes, %d bytes remain.\n",
+		result.RemovedEntries, result.RemovedObjects, result.FreedBytes, result.RemainingBytes,
+	)
+	return nil
+}
+
 func version() string {
 	v := "dev"
 	if info, ok := debug.ReadBuildInfo(); ok {
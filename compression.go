@@ -0,0 +1,207 @@
+package pageseo
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dkotik/pageseo/internal"
+)
+
+// ContentDecoder removes a content encoding from the stream.
+type ContentDecoder func(io.Reader) (io.ReadCloser, error)
+
+var (
+	contentDecodersMu sync.RWMutex
+	contentEncodings  = []string{"gzip", "deflate"}
+	contentDecoders   = map[string]ContentDecoder{
+		"gzip": func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+		"deflate": decodeDeflate,
+	}
+)
+
+// RegisterContentDecoder adds support for a content encoding,
+// like "br" or "zstd", to the HTTP loader. The loader
+// requests every registered encoding in the
+// <Accept-Encoding> header. Encodings registered later
+// are preferred.
+func RegisterContentDecoder(encoding string, decoder ContentDecoder) {
+	if decoder == nil {
+		panic("nil content decoder")
+	}
+	encoding = strings.ToLower(strings.TrimSpace(encoding))
+	contentDecodersMu.Lock()
+	defer contentDecodersMu.Unlock()
+	if _, ok := contentDecoders[encoding]; !ok {
+		contentEncodings = slices.Insert(contentEncodings, 0, encoding)
+	}
+	contentDecoders[encoding] = decoder
+}
+
+// acceptEncoding lists the registered content encodings.
+func acceptEncoding() string {
+	contentDecodersMu.RLock()
+	defer contentDecodersMu.RUnlock()
+	return strings.Join(contentEncodings, ", ")
+}
+
+// decodeDeflate accepts both the zlib stream required by the
+// standard and the raw deflate stream some servers send.
+func decodeDeflate(r io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(r)
+	header, _ := buffered.Peek(2)
+	if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+	return flate.NewReader(buffered), nil
+}
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// parseContentEncoding lists the encodings of the
+// <Content-Encoding> header in the order they were applied.
+func parseContentEncoding(header string) (encodings []string) {
+	for encoding := range strings.SplitSeq(header, ",") {
+		encoding = strings.ToLower(strings.TrimSpace(encoding))
+		switch encoding {
+		case "", "identity":
+			continue
+		case "x-gzip":
+			encoding = "gzip"
+		}
+		encodings = append(encodings, encoding)
+	}
+	return encodings
+}
+
+// ContentEncoding returns the encodings listed by every
+// <Content-Encoding> header line in the order they were
+// applied, normalized like [Response.ContentEncoding].
+func ContentEncoding(header http.Header) string {
+	return strings.Join(parseContentEncoding(strings.Join(header.Values("Content-Encoding"), ",")), ", ")
+}
+
+// decodedStream closes the decoders of every
+// removed encoding, the innermost one first.
+type decodedStream struct {
+	io.Reader
+	decoders []io.Closer
+}
+
+func (d *decodedStream) Close() (err error) {
+	for _, decoder := range slices.Backward(d.decoders) {
+		err = errors.Join(err, decoder.Close())
+	}
+	return err
+}
+
+// DecodeContent removes the encodings listed by the
+// <Content-Encoding> header from the stream using the
+// registered [ContentDecoder]s. It returns the removed
+// encodings like [Response.ContentEncoding], which the
+// compression checks compare with the header.
+func DecodeContent(header http.Header, r io.Reader) (io.ReadCloser, string, error) {
+	stream, decoded, err := decodeContent(strings.Join(header.Values("Content-Encoding"), ","), bufio.NewReader(r))
+	if err != nil {
+		return nil, "", err
+	}
+	return stream, strings.Join(decoded, ", "), nil
+}
+
+// decodeContent removes the content encodings, the last
+// applied one first, and lists the removed ones. Decoding
+// stops at an encoding that is not supported or that the
+// content does not match, which is reported by
+// [validateCompression].
+func decodeContent(header string, r *bufio.Reader) (_ io.ReadCloser, decoded []string, err error) {
+	stream := &decodedStream{Reader: r}
+	encodings := parseContentEncoding(header)
+	for i := len(encodings) - 1; i >= 0; i-- {
+		buffered, ok := stream.Reader.(*bufio.Reader)
+		if !ok {
+			buffered = bufio.NewReader(stream.Reader)
+			stream.Reader = buffered
+		}
+		if _, err = buffered.Peek(1); err != nil {
+			break // empty content
+		}
+		contentDecodersMu.RLock()
+		decoder, ok := contentDecoders[encodings[i]]
+		contentDecodersMu.RUnlock()
+		if !ok {
+			break
+		}
+		if encodings[i] == "gzip" {
+			if magic, _ := buffered.Peek(2); !bytes.Equal(magic, gzipMagic) {
+				break // mislabeled
+			}
+		}
+		decompressed, err := decoder(buffered)
+		if err != nil {
+			return nil, nil, errors.Join(err, stream.Close())
+		}
+		stream.Reader = decompressed
+		stream.decoders = append(stream.decoders, decompressed)
+		decoded = encodings[i:]
+	}
+	return stream, decoded, nil
+}
+
+// countingReader counts the bytes received before decoding.
+type countingReader struct {
+	io.Reader
+	Count int64
+}
+
+func (c *countingReader) Read(p []byte) (n int, err error) {
+	n, err = c.Reader.Read(p)
+	c.Count += int64(n)
+	return n, err
+}
+
+// isCompressible returns true for text formats
+// that shrink considerably when compressed.
+func isCompressible(contentType string) bool {
+	switch contentType {
+	case "application/javascript", "application/json", "application/ld+json",
+		"application/manifest+json", "application/xml", "application/rss+xml",
+		"application/atom+xml", "image/svg+xml":
+		return true
+	default:
+		return strings.HasPrefix(contentType, "text/")
+	}
+}
+
+// validateCompression reports text assets served without
+// compression, and compression that browsers cannot undo.
+// Responses without HTTP headers are not checked.
+func validateCompression(t testing.TB, location string, response *Response) {
+	if response == nil || response.StatusCode == 0 || response.Header == nil || len(response.Content) == 0 {
+		return
+	}
+	label := ContentEncoding(response.Header)
+	switch {
+	case label != response.ContentEncoding:
+		t.Errorf("<Content-Encoding: %s> of %q does not match the content or was not requested", label, location)
+	case response.Truncated:
+	case bytes.HasPrefix(response.Content, gzipMagic) && isCompressible(response.ContentType):
+		if response.ContentEncoding != "" {
+			t.Errorf("%q is compressed twice", location)
+		} else {
+			t.Errorf("%q is compressed without <Content-Encoding> header", location)
+		}
+	case response.ContentEncoding == "" && isCompressible(response.ContentType) && len(response.Content) > DefaultMaximumUncompressedBytes:
+		t.Logf("%s %q is served without compression: %d bytes", internal.WP, location, len(response.Content))
+	}
+}
@@ -0,0 +1,144 @@
+package pageseo
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	var b bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&b)
+	case "zlib":
+		w = zlib.NewWriter(&b)
+	case "deflate":
+		w, _ = flate.NewWriter(&b, flate.DefaultCompression)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestCompression(t *testing.T) {
+	style := []byte(strings.Repeat("body { color: black; }\n", 100))
+	bodies := map[string][]byte{
+		"/gzip.css":    compress(t, "gzip", style),
+		"/zlib.css":    compress(t, "zlib", style),
+		"/deflate.css": compress(t, "deflate", style),
+		"/double.css":  compress(t, "gzip", compress(t, "gzip", style)),
+		"/label.css":   style,
+		"/plain.css":   style,
+		"/hidden.css":  compress(t, "gzip", style),
+		"/stacked.css": compress(t, "gzip", compress(t, "deflate", style)),
+		"/partial.css": compress(t, "deflate", style),
+	}
+	accepted := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accepted = r.Header.Get("Accept-Encoding")
+		w.Header().Set("Content-Type", "text/css")
+		switch r.URL.Path {
+		case "/gzip.css", "/double.css", "/label.css":
+			w.Header().Set("Content-Encoding", "gzip")
+		case "/zlib.css", "/deflate.css":
+			w.Header().Set("Content-Encoding", "deflate")
+		case "/stacked.css":
+			w.Header().Set("Content-Encoding", "deflate, gzip")
+		case "/partial.css":
+			w.Header().Add("Content-Encoding", "gzip")
+			w.Header().Add("Content-Encoding", "deflate")
+		}
+		_, _ = w.Write(bodies[r.URL.Path])
+	}))
+	t.Cleanup(server.Close)
+	loader := NewHTTPClient(server.Client(), nil)
+
+	for path, expected := range map[string]struct {
+		Encoding string
+		Errors   int
+		Logs     int
+	}{
+		"/gzip.css":    {Encoding: "gzip"},
+		"/zlib.css":    {Encoding: "deflate"},
+		"/deflate.css": {Encoding: "deflate"},
+		"/double.css":  {Encoding: "gzip", Errors: 1},
+		"/label.css":   {Errors: 1},
+		"/plain.css":   {Logs: 1},
+		"/hidden.css":  {Errors: 1},
+		"/stacked.css": {Encoding: "deflate, gzip"},
+		"/partial.css": {Encoding: "deflate", Errors: 1},
+	} {
+		t.Run(path, func(t *testing.T) {
+			response, err := LoadResponse(t.Context(), loader, server.URL+path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if accepted != "gzip, deflate" {
+				t.Fatal("compressed encodings were not requested:", accepted)
+			}
+			if response.ContentEncoding != expected.Encoding {
+				t.Fatalf("content encoding %q does not match expected %q", response.ContentEncoding, expected.Encoding)
+			}
+			if response.TransferSize != int64(len(bodies[path])) {
+				t.Fatal("unexpected transfer size:", response.TransferSize)
+			}
+			if expected.Encoding != "" && path != "/double.css" && path != "/partial.css" && !bytes.Equal(response.Content, style) {
+				t.Fatal("content was not decoded")
+			}
+
+			recorder := &recordingTB{TB: t}
+			validateCompression(recorder, path, response)
+			if len(recorder.Errors) != expected.Errors || len(recorder.Logs) != expected.Logs {
+				t.Fatalf("unexpected reports: %q %q", recorder.Errors, recorder.Logs)
+			}
+		})
+	}
+}
+
+// pageLoadCounter counts the loads of a page
+// and skips the others.
+type pageLoadCounter struct {
+	Page  string
+	Loads atomic.Int32
+}
+
+func (c *pageLoadCounter) Load(_ context.Context, URL string) ([]byte, string, error) {
+	if URL == c.Page {
+		c.Loads.Add(1)
+	}
+	return nil, "", Skip
+}
+
+func TestResponseIsNotLoadedAgain(t *testing.T) {
+	page, err := os.ReadFile(filepath.Join("testdata", "minimal.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader := &pageLoadCounter{Page: "https://example.com/"}
+	t.Run("minimal", New(loader).TestResponse(&Response{
+		URL:         loader.Page,
+		StatusCode:  http.StatusOK,
+		Header:      http.Header{"Content-Type": {"text/html"}},
+		ContentType: "text/html",
+		Content:     page,
+	}))
+	if loads := loader.Loads.Load(); loads != 0 {
+		t.Fatal("tested page was loaded again:", loads)
+	}
+}
@@ -12,6 +12,7 @@ func (c *crawler) CrawlLocation(ctx context.Context, URL string) (err error) {
 	if !strings.HasSuffix(URL, "/") {
 		URL += "/"
 	}
+	c.Scope.Add(URL)
 	cursor := repository.Cursor{
 		LikeFilter: URL + "%",
 		ID:         0,
@@ -37,6 +38,10 @@ func (c *crawler) CrawlLocation(ctx context.Context, URL string) (err error) {
 				}
 				t.UpdatedAt = time.Now()
 			}
+			// links are recorded again during analysis
+			if err = c.Repository.ForgetPageLinks(ctx, t.Location); err != nil {
+				return err
+			}
 			if err = c.Analyzer.Analyze(ctx, t); err != nil {
 				return err
 			}
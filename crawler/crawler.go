@@ -9,12 +9,17 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/dkotik/pageseo"
+	"github.com/dkotik/pageseo/crawler/linkgraph"
 	"github.com/dkotik/pageseo/crawler/repository"
 	sqr "github.com/dkotik/pageseo/crawler/repository/sqlite"
+	"github.com/dkotik/pageseo/robots"
 )
 
 type Analyzer interface {
@@ -29,10 +34,17 @@ func (f AnalyzerFunc) Analyze(ctx context.Context, t repository.Target) error {
 
 type Crawler interface {
 	pageseo.Loader
+	pageseo.ResponseLoader
+	pageseo.PageLinkRecorder
 	CrawlLocation(context.Context, string) error
+
+	// LinkGraph assembles the internal links recorded
+	// while crawling the location.
+	LinkGraph(context.Context, string) (*linkgraph.Graph, error)
 }
 
 type crawler struct {
+	Scope      *crawlScope
 	Analyzer   Analyzer
 	Repository repository.Repository
 	BatchSize  int
@@ -42,6 +54,7 @@ type crawler struct {
 
 func New(analyzer Analyzer, withOptions ...Option) (_ Crawler, err error) {
 	o := options{}
+	scope := &crawlScope{}
 	for _, option := range append(
 		slices.Grow(withOptions, len(withOptions)+1),
 		func(o options) (_ options, err error) {
@@ -62,10 +75,25 @@ func New(analyzer Analyzer, withOptions ...Option) (_ Crawler, err error) {
 				if o.SQLiteConn == nil {
 					return o, errors.New("SQLite connection is required when a repository is not provided")
 				}
-				loader := newClientPool(8)
+				loader := o.Loader
+				if loader == nil {
+					loader = newClientPool(8)
+				}
+				for _, m := range o.Middleware {
+					loader = m.WrapLoader(loader)
+				}
 				if o.Delay != 0 || o.DelayFluctuate != 0 {
 					loader = pageseo.NewDelay(o.Delay, o.DelayFluctuate).WrapLoader(loader)
 				}
+				if o.RateLimit != nil {
+					loader = pageseo.NewRateLimit(*o.RateLimit).WrapLoader(loader)
+				}
+				if o.UserAgent != "" {
+					loader = robots.NewMiddlewareWithConstraints(robots.MiddlewareConstraints{
+						UserAgent: o.UserAgent,
+						IsCrawled: scope.Contains,
+					}).WrapLoader(loader)
+				}
 				o.Repository, err = sqr.New(
 					o.SQLiteConn,
 					loader,
@@ -85,6 +113,7 @@ func New(analyzer Analyzer, withOptions ...Option) (_ Crawler, err error) {
 		}
 	}
 	c := &crawler{
+		Scope:      scope,
 		Analyzer:   analyzer,
 		Repository: o.Repository,
 		BatchSize:  o.BatchSize,
@@ -97,3 +126,49 @@ func New(analyzer Analyzer, withOptions ...Option) (_ Crawler, err error) {
 func (c *crawler) Load(ctx context.Context, URL string) ([]byte, string, error) {
 	return c.Repository.Load(ctx, URL)
 }
+
+func (c *crawler) LoadResponse(ctx context.Context, URL string) (*pageseo.Response, error) {
+	return pageseo.LoadResponse(ctx, c.Repository, URL)
+}
+
+func (c *crawler) TraceRedirects(URL string) []pageseo.Redirect {
+	return pageseo.TraceRedirects(c.Repository, URL)
+}
+
+func (c *crawler) RecordPageLink(ctx context.Context, link pageseo.PageLink) error {
+	return c.Repository.RecordPageLink(ctx, link)
+}
+
+func (c *crawler) LinkGraph(ctx context.Context, URL string) (*linkgraph.Graph, error) {
+	if !strings.HasSuffix(URL, "/") {
+		URL += "/"
+	}
+	links, err := c.Repository.GetPageLinks(ctx, URL+"%")
+	if err != nil {
+		return nil, err
+	}
+	return linkgraph.New(URL, links), nil
+}
+
+// crawlScope collects the locations passed to CrawlLocation.
+// Other resources are loaded only to validate crawled pages.
+type crawlScope struct {
+	mu       sync.RWMutex
+	prefixes []string
+}
+
+func (s *crawlScope) Add(prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !slices.Contains(s.prefixes, prefix) {
+		s.prefixes = append(s.prefixes, prefix)
+	}
+}
+
+func (s *crawlScope) Contains(location *url.URL) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.ContainsFunc(s.prefixes, func(prefix string) bool {
+		return strings.HasPrefix(location.String(), prefix)
+	})
+}
@@ -0,0 +1,62 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dkotik/pageseo"
+	"github.com/dkotik/pageseo/crawler/repository"
+	"zombiezen.com/go/sqlite"
+)
+
+func TestCrawlMediaLink(t *testing.T) {
+	mu := sync.Mutex{}
+	methods := make([]string, 0, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/video.mp4" {
+			mu.Lock()
+			methods = append(methods, r.Method)
+			mu.Unlock()
+			w.Header().Set("Content-Type", "video/mp4")
+			_, _ = w.Write([]byte(strings.Repeat("a", 1<<20)))
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body><a href="/video.mp4">Lorem ipsum</a></body></html>`))
+	}))
+	t.Cleanup(server.Close)
+
+	conn, err := sqlite.OpenConn(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	var cr Crawler
+	cr, err = New(AnalyzerFunc(func(ctx context.Context, target repository.Target) error {
+		// like the anchor tester checking the link
+		_, err := pageseo.LoadResponse(ctx, cr, server.URL+"/video.mp4")
+		return err
+	}), WithSQLiteConn(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = cr.CrawlLocation(t.Context(), server.URL); err != nil {
+		t.Fatal(err)
+	}
+	if len(methods) != 1 || methods[0] != http.MethodHead {
+		t.Fatal("video was downloaded to check the link:", methods)
+	}
+
+	response, err := pageseo.LoadResponse(t.Context(), cr, server.URL+"/video.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !response.Cached || !response.Truncated || response.ContentType != "video/mp4" {
+		t.Fatalf("stored media response lost its details: %+v", response)
+	}
+}
@@ -7,8 +7,12 @@ import (
 	"github.com/dkotik/pageseo"
 )
 
+// UserAgent identifies the crawler to servers. Its product
+// token, "pageseo", selects the robots.txt group.
+const UserAgent = "pageseo/1.0 (+https://github.com/dkotik/pageseo)"
+
 var headers = http.Header{
-	"User-Agent":      []string{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36"},
+	"User-Agent":      []string{UserAgent},
 	"Accept-Language": []string{"en-US,en;q=0.9"},
 }
 
@@ -19,6 +23,14 @@ func newClientHTTP() (client *http.Client) {
 	return client
 }
 
+// NewLoader creates the HTTP loader of a single crawler
+// connection. It sends [UserAgent], gives up on slow
+// servers, and does not download media files, which
+// are only checked for existence and type.
+func NewLoader() pageseo.Loader {
+	return pageseo.NewHTTPClientWithSizeLimit(newClientHTTP(), headers, pageseo.SizeLimitConstraints{})
+}
+
 func newClientPool(depth uint8) pageseo.Loader {
 	retry := pageseo.NewRetry(3)
 	// delay := NewDelay(time.Second, time.Millisecond*700)
@@ -26,14 +38,14 @@ func newClientPool(depth uint8) pageseo.Loader {
 	case 0:
 		panic("zero clients")
 	case 1:
-		return pageseo.NewSingleFlightLoader(retry.WrapLoader(pageseo.NewHTTPClient(newClientHTTP(), headers)))
+		return pageseo.NewSingleFlightLoader(retry.WrapLoader(NewLoader()))
 	}
 
 	loaders := make([]pageseo.Loader, depth)
 	for i := range depth {
 		loaders[i] = retry.WrapLoader(
 			// delay.WrapLoader(
-			pageseo.NewHTTPClient(newClientHTTP(), headers),
+			NewLoader(),
 			// ),
 		)
 	}
@@ -0,0 +1,34 @@
+package crawler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dkotik/pageseo/robots"
+)
+
+func TestUserAgentMatchesRobotsGroup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.UserAgent() != UserAgent {
+			t.Error("unexpected user agent:", r.UserAgent())
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		if r.URL.Path == "/robots.txt" {
+			_, _ = w.Write([]byte("User-agent: *\nAllow: /\n\nUser-agent: pageseo\nDisallow: /private\n"))
+			return
+		}
+		_, _ = w.Write([]byte("Lorem ipsum."))
+	}))
+	t.Cleanup(server.Close)
+
+	loader := robots.NewMiddleware(UserAgent).WrapLoader(NewLoader())
+	if _, _, err := loader.Load(t.Context(), server.URL+"/public"); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err := loader.Load(t.Context(), server.URL+"/private")
+	if !errors.Is(err, robots.ErrDisallowed) {
+		t.Fatal("robots.txt group of the user agent was not applied:", err)
+	}
+}
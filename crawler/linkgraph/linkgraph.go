@@ -0,0 +1,198 @@
+/*
+Package linkgraph analyzes the internal links between
+pages discovered by the crawler.
+*/
+package linkgraph
+
+import (
+	"cmp"
+	"math"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/dkotik/pageseo"
+)
+
+const (
+	DefaultMaximumClickDepth   = 3
+	DefaultMinimumInboundLinks = 2
+	DefaultDamping             = 0.85
+	DefaultIterations          = 50
+)
+
+// Graph holds the internal links of a site. Locations
+// are normalized, see [Normalize].
+type Graph struct {
+	Start    string
+	Outbound map[string][]string
+	Inbound  map[string][]string
+
+	// Followed excludes links with rel="nofollow",
+	// which do not pass importance.
+	Followed map[string][]string
+}
+
+// Normalize removes the fragment, lowercases the host,
+// and replaces an empty path with "/". It returns an
+// empty string for locations that cannot be parsed.
+func Normalize(location string) string {
+	parsed, err := url.Parse(strings.TrimSpace(location))
+	if err != nil {
+		return ""
+	}
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+	parsed.Host = strings.ToLower(parsed.Host)
+	if parsed.Path == "" {
+		parsed.Path = "/"
+	}
+	return parsed.String()
+}
+
+// New builds a graph from the links that point to the
+// same host as the start location. Self links are ignored.
+func New(start string, links []pageseo.PageLink) *Graph {
+	g := &Graph{
+		Start:    Normalize(start),
+		Outbound: make(map[string][]string),
+		Inbound:  make(map[string][]string),
+		Followed: make(map[string][]string),
+	}
+	host := ""
+	if parsed, err := url.Parse(g.Start); err == nil {
+		host = parsed.Host
+	}
+	g.Outbound[g.Start] = nil
+
+	for _, link := range links {
+		source, target := Normalize(link.Source), Normalize(link.Target)
+		if source == "" || target == "" || source == target {
+			continue
+		}
+		parsed, err := url.Parse(target)
+		if err != nil || parsed.Host != host {
+			continue // external link
+		}
+		if _, ok := g.Outbound[target]; !ok {
+			g.Outbound[target] = nil
+		}
+		if slices.Contains(g.Outbound[source], target) {
+			continue // count each page link once
+		}
+		g.Outbound[source] = append(g.Outbound[source], target)
+		g.Inbound[target] = append(g.Inbound[target], source)
+		if !slices.Contains(link.Rel, "nofollow") {
+			g.Followed[source] = append(g.Followed[source], target)
+		}
+	}
+	return g
+}
+
+// Locations returns every known page in a stable order.
+func (g *Graph) Locations() []string {
+	locations := make([]string, 0, len(g.Outbound))
+	for location := range g.Outbound {
+		locations = append(locations, location)
+	}
+	slices.Sort(locations)
+	return locations
+}
+
+// ClickDepth returns the least number of clicks needed
+// to reach each page from the start location. Unreachable
+// pages are absent from the result.
+func (g *Graph) ClickDepth() map[string]int {
+	depth := map[string]int{g.Start: 0}
+	queue := []string{g.Start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range g.Outbound[current] {
+			if _, ok := depth[next]; ok {
+				continue
+			}
+			depth[next] = depth[current] + 1
+			queue = append(queue, next)
+		}
+	}
+	return depth
+}
+
+// Importance distributes a PageRank-style score over the
+// pages through followed links. Scores add up to one.
+// Pages without outbound links spread their score evenly.
+func (g *Graph) Importance(damping float64, iterations int) map[string]float64 {
+	locations := g.Locations()
+	total := float64(len(locations))
+	rank := make(map[string]float64, len(locations))
+	for _, location := range locations {
+		rank[location] = 1 / total
+	}
+
+	for range iterations {
+		next := make(map[string]float64, len(locations))
+		dangling := 0.0
+		for _, location := range locations {
+			targets := g.Followed[location]
+			if len(targets) == 0 {
+				dangling += rank[location]
+				continue
+			}
+			share := rank[location] / float64(len(targets))
+			for _, target := range targets {
+				next[target] += share
+			}
+		}
+		change := 0.0
+		for _, location := range locations {
+			score := (1-damping)/total + damping*(next[location]+dangling/total)
+			change += math.Abs(score - rank[location])
+			next[location] = score
+		}
+		rank = next
+		if change < 1e-9 {
+			break
+		}
+	}
+	return rank
+}
+
+// Orphans returns the locations that no other known
+// page links to, excluding the start location.
+func (g *Graph) Orphans(locations []string) (orphans []string) {
+	for _, location := range locations {
+		location = Normalize(location)
+		if location == "" || location == g.Start {
+			continue
+		}
+		if len(g.Inbound[location]) == 0 && !slices.Contains(orphans, location) {
+			orphans = append(orphans, location)
+		}
+	}
+	return orphans
+}
+
+// RankedLocation is a page with its importance score.
+type RankedLocation struct {
+	Location   string
+	Importance float64
+}
+
+// Rank orders pages by descending importance.
+func Rank(importance map[string]float64) []RankedLocation {
+	ranked := make([]RankedLocation, 0, len(importance))
+	for location, score := range importance {
+		ranked = append(ranked, RankedLocation{
+			Location:   location,
+			Importance: score,
+		})
+	}
+	slices.SortFunc(ranked, func(a, b RankedLocation) int {
+		if c := cmp.Compare(b.Importance, a.Importance); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Location, b.Location)
+	})
+	return ranked
+}
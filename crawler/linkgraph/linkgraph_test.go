@@ -0,0 +1,64 @@
+package linkgraph
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/dkotik/pageseo"
+)
+
+func TestGraph(t *testing.T) {
+	g := New("https://Example.com", []pageseo.PageLink{
+		{Source: "https://example.com/", Target: "https://example.com/a"},
+		{Source: "https://example.com/", Target: "https://example.com/b#top"},
+		{Source: "https://example.com/a", Target: "https://example.com/c"},
+		{Source: "https://example.com/a", Target: "https://example.com/c"}, // duplicate
+		{Source: "https://example.com/c", Target: "https://example.com/d", Rel: []string{"nofollow"}},
+		{Source: "https://example.com/c", Target: "https://example.com/c"}, // self
+		{Source: "https://example.com/b", Target: "https://other.com/"},    // external
+	})
+
+	depth := g.ClickDepth()
+	for location, expected := range map[string]int{
+		"https://example.com/":  0,
+		"https://example.com/a": 1,
+		"https://example.com/b": 1,
+		"https://example.com/c": 2,
+		"https://example.com/d": 3,
+	} {
+		if depth[location] != expected {
+			t.Errorf("click depth of %q is %d, expected %d", location, depth[location], expected)
+		}
+	}
+	if len(g.Locations()) != 5 {
+		t.Fatal("unexpected locations:", g.Locations())
+	}
+	if inbound := len(g.Inbound["https://example.com/c"]); inbound != 1 {
+		t.Fatal("duplicate links were counted:", inbound)
+	}
+
+	importance := g.Importance(DefaultDamping, DefaultIterations)
+	total := 0.0
+	for _, score := range importance {
+		total += score
+	}
+	if math.Abs(total-1) > 1e-6 {
+		t.Fatal("importance scores do not add up to one:", total)
+	}
+	if importance["https://example.com/c"] <= importance["https://example.com/d"] {
+		t.Fatal("nofollow link passed importance")
+	}
+	if ranked := Rank(importance); ranked[0].Location == "https://example.com/d" {
+		t.Fatal("unexpected most important page:", ranked[0])
+	}
+
+	orphans := g.Orphans([]string{
+		"https://example.com/",
+		"https://example.com/a",
+		"https://example.com/forgotten",
+	})
+	if !slices.Equal(orphans, []string{"https://example.com/forgotten"}) {
+		t.Fatal("unexpected orphans:", orphans)
+	}
+}
@@ -0,0 +1,61 @@
+package linkgraph
+
+import (
+	"testing"
+
+	"github.com/dkotik/pageseo/internal"
+)
+
+// Constraints configure link graph [Test].
+type Constraints struct {
+	MaximumClickDepth   int
+	MinimumInboundLinks int
+
+	// SiteMapLocations are the pages that must be
+	// reachable through internal links.
+	SiteMapLocations []string
+}
+
+// Test reports orphan pages, pages buried too deep,
+// and pages with too few inbound links. It logs the
+// most and least important pages.
+func Test(g *Graph, c Constraints) func(*testing.T) {
+	if c.MaximumClickDepth < 1 {
+		c.MaximumClickDepth = DefaultMaximumClickDepth
+	}
+	if c.MinimumInboundLinks < 1 {
+		c.MinimumInboundLinks = DefaultMinimumInboundLinks
+	}
+	return func(t *testing.T) {
+		if g == nil {
+			t.Fatal("nil link graph")
+		}
+		for _, orphan := range g.Orphans(c.SiteMapLocations) {
+			t.Errorf("page <%s> is listed in the sitemap, but no page links to it", orphan)
+		}
+
+		depth := g.ClickDepth()
+		for _, location := range g.Locations() {
+			clicks, ok := depth[location]
+			switch {
+			case !ok:
+				if len(g.Inbound[location]) > 0 {
+					t.Errorf("page <%s> cannot be reached from <%s>", location, g.Start)
+				}
+			case clicks > c.MaximumClickDepth:
+				t.Errorf("page <%s> is %d clicks away from <%s>, expected %d or less", location, clicks, g.Start, c.MaximumClickDepth)
+			}
+			if location == g.Start {
+				continue
+			}
+			if inbound := len(g.Inbound[location]); inbound > 0 && inbound < c.MinimumInboundLinks {
+				t.Logf("%s page <%s> has %d inbound internal links, expected %d or more", internal.WP, location, inbound, c.MinimumInboundLinks)
+			}
+		}
+
+		ranked := Rank(g.Importance(DefaultDamping, DefaultIterations))
+		for i, page := range ranked[:min(len(ranked), 10)] {
+			t.Logf("#%d most important page: <%s> %.4f", i+1, page.Location, page.Importance)
+		}
+	}
+}
@@ -3,8 +3,10 @@ package crawler
 import (
 	"errors"
 	"log/slog"
+	"strings"
 	"time"
 
+	"github.com/dkotik/pageseo"
 	"github.com/dkotik/pageseo/crawler/repository"
 	"zombiezen.com/go/sqlite"
 )
@@ -13,6 +15,10 @@ type options struct {
 	// Filter  Filter
 	Delay          time.Duration
 	DelayFluctuate time.Duration
+	RateLimit      *pageseo.RateLimitConstraints
+	UserAgent      string
+	Loader         pageseo.Loader
+	Middleware     []pageseo.Middleware
 	SQLiteConn     *sqlite.Conn
 	Repository     repository.Repository
 	TimeToLive     time.Duration
@@ -36,6 +42,59 @@ func WithDelay(base, fluctuation time.Duration) Option {
 	}
 }
 
+// WithRateLimit throttles requests per host.
+func WithRateLimit(c pageseo.RateLimitConstraints) Option {
+	return func(o options) (options, error) {
+		if o.RateLimit != nil {
+			return o, errors.New("rate limit is already set")
+		}
+		o.RateLimit = &c
+		return o, nil
+	}
+}
+
+// WithLoader replaces the HTTP client that loads targets,
+// for example, with a replayed archive.
+func WithLoader(l pageseo.Loader) Option {
+	return func(o options) (options, error) {
+		if l == nil {
+			return o, errors.New("nil loader")
+		}
+		if o.Loader != nil {
+			return o, errors.New("loader is already set")
+		}
+		o.Loader = l
+		return o, nil
+	}
+}
+
+// WithMiddleware wraps the HTTP client that loads targets.
+// Middleware added first is applied closest to the client.
+func WithMiddleware(m pageseo.Middleware) Option {
+	return func(o options) (options, error) {
+		if m == nil {
+			return o, errors.New("nil middleware")
+		}
+		o.Middleware = append(o.Middleware, m)
+		return o, nil
+	}
+}
+
+// WithRobotsTxt skips the locations that robots.txt
+// disallows for the user agent.
+func WithRobotsTxt(userAgent string) Option {
+	return func(o options) (options, error) {
+		if strings.TrimSpace(userAgent) == "" {
+			return o, errors.New("empty user agent")
+		}
+		if o.UserAgent != "" {
+			return o, errors.New("robots.txt user agent is already set")
+		}
+		o.UserAgent = userAgent
+		return o, nil
+	}
+}
+
 func WithSQLiteConn(conn *sqlite.Conn) Option {
 	return func(o options) (options, error) {
 		if conn == nil {
@@ -26,8 +26,17 @@ type Cursor struct {
 
 type Repository interface {
 	pageseo.Loader
+	pageseo.PageLinkRecorder
 	GetTargetBatch(context.Context, Cursor) ([]Target, error)
 	MarkAsAnalyzed(context.Context, int64) error
+
+	// GetPageLinks returns recorded links with
+	// source locations matching the LIKE filter.
+	GetPageLinks(context.Context, string) ([]pageseo.PageLink, error)
+
+	// ForgetPageLinks removes the links recorded
+	// for a source location before it is analyzed again.
+	ForgetPageLinks(context.Context, string) error
 }
 
 func Test(t *testing.T, c Repository) {
@@ -80,4 +89,41 @@ func Test(t *testing.T, c Repository) {
 	if len(targets) != 0 {
 		t.Fatal("expected 0 targets, got:", len(targets))
 	}
+
+	link := pageseo.PageLink{
+		Source: "http://localhost/",
+		Target: "http://localhost/about",
+		Text:   "About",
+		Rel:    []string{"author"},
+	}
+	if err = c.RecordPageLink(ctx, link); err != nil {
+		t.Fatal(err)
+	}
+	if err = c.RecordPageLink(ctx, link); err != nil {
+		t.Fatal("recording the same link twice failed:", err)
+	}
+	links, err := c.GetPageLinks(ctx, "http://localhost/%")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 1 {
+		t.Fatal("expected 1 link, got:", len(links))
+	}
+	if links[0].Target != link.Target || links[0].Text != link.Text {
+		t.Fatal("wrong link:", links[0])
+	}
+	if len(links[0].Rel) != 1 || links[0].Rel[0] != "author" {
+		t.Fatal("wrong link relationship:", links[0].Rel)
+	}
+
+	if err = c.ForgetPageLinks(ctx, link.Source); err != nil {
+		t.Fatal(err)
+	}
+	links, err = c.GetPageLinks(ctx, "http://localhost/%")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 0 {
+		t.Fatal("expected 0 links, got:", len(links))
+	}
 }
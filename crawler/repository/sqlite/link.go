@@ -0,0 +1,78 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/dkotik/pageseo"
+)
+
+func (c *sqliteRepository) RecordPageLink(ctx context.Context, link pageseo.PageLink) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err = c.stmtLinkPush.Reset(); err != nil {
+		return err
+	}
+
+	// source, target, anchor_text, rel, created_at
+	c.stmtLinkPush.BindText(1, link.Source)
+	c.stmtLinkPush.BindText(2, link.Target)
+	c.stmtLinkPush.BindText(3, link.Text)
+	c.stmtLinkPush.BindText(4, strings.Join(link.Rel, " "))
+	c.stmtLinkPush.BindText(5, encodeTime(time.Now()))
+
+	var ok bool
+	for {
+		ok, err = c.stmtLinkPush.Step()
+		if err != nil || !ok {
+			break
+		}
+	}
+	return err
+}
+
+func (c *sqliteRepository) GetPageLinks(ctx context.Context, likeFilter string) (links []pageseo.PageLink, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err = c.stmtLinkList.Reset(); err != nil {
+		return nil, err
+	}
+	c.stmtLinkList.BindText(1, likeFilter)
+
+	var ok bool
+	for {
+		ok, err = c.stmtLinkList.Step()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		// source, target, anchor_text, rel
+		links = append(links, pageseo.PageLink{
+			Source: c.stmtLinkList.ColumnText(0),
+			Target: c.stmtLinkList.ColumnText(1),
+			Text:   c.stmtLinkList.ColumnText(2),
+			Rel:    strings.Fields(c.stmtLinkList.ColumnText(3)),
+		})
+	}
+	return links, nil
+}
+
+func (c *sqliteRepository) ForgetPageLinks(ctx context.Context, source string) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err = c.stmtLinkForget.Reset(); err != nil {
+		return err
+	}
+	c.stmtLinkForget.BindText(1, source)
+
+	var ok bool
+	for {
+		ok, err = c.stmtLinkForget.Step()
+		if err != nil || !ok {
+			return err
+		}
+	}
+}
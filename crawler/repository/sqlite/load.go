@@ -2,16 +2,49 @@ package sqlite
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"strings"
 	"time"
+
+	"github.com/dkotik/pageseo"
+	"zombiezen.com/go/sqlite"
 )
 
-func (c *sqliteRepository) load(ctx context.Context, URL string) (content []byte, contentType string, err error) {
+// responseColumns are read by [scanResponse] in this order.
+const responseColumns = `content_type, content, status_code, header, content_encoding, redirects, truncated`
+
+func scanResponse(stmt *sqlite.Stmt, URL string) (response *pageseo.Response, err error) {
+	response = &pageseo.Response{
+		URL:             URL,
+		FinalURL:        URL,
+		StatusCode:      int(stmt.ColumnInt64(2)),
+		ContentType:     stmt.ColumnText(0),
+		Content:         make([]byte, stmt.ColumnLen(1)),
+		ContentEncoding: stmt.ColumnText(4),
+		Cached:          true,
+		Truncated:       stmt.ColumnBool(6),
+	}
+	_ = stmt.ColumnBytes(1, response.Content)
+	if header := stmt.ColumnText(3); header != "" {
+		if err = json.Unmarshal([]byte(header), &response.Header); err != nil {
+			return nil, err
+		}
+	}
+	if response.Redirects, err = decodeRedirects(stmt.ColumnText(5)); err != nil {
+		return nil, err
+	}
+	if total := len(response.Redirects); total > 0 {
+		response.FinalURL = response.Redirects[total-1].To
+	}
+	return response, nil
+}
+
+func (c *sqliteRepository) load(ctx context.Context, URL string) (response *pageseo.Response, err error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	if err = c.stmtPull.Reset(); err != nil {
-		return nil, "", err
+		return nil, err
 	}
 	c.stmtPull.BindText(1, URL)
 	c.stmtPull.BindText(2, encodeTime(time.Now().Add(c.TimeToLive)))
@@ -19,51 +52,149 @@ func (c *sqliteRepository) load(ctx context.Context, URL string) (content []byte
 	for {
 		ok, err = c.stmtPull.Step()
 		if err != nil {
-			return nil, "", err
+			return nil, err
 		}
 		if !ok {
 			break
 		}
-		contentType = c.stmtPull.ColumnText(0)
-		content = make([]byte, c.stmtPull.ColumnLen(1))
-		_ = c.stmtPull.ColumnBytes(1, content)
+		if response, err = scanResponse(c.stmtPull, URL); err != nil {
+			return nil, err
+		}
 	}
-	if contentType == "" {
-		return nil, "", os.ErrNotExist
+	if response == nil || response.ContentType == "" {
+		return nil, os.ErrNotExist
 	}
-	return content, contentType, nil
+	return response, nil
 }
 
-func (c *sqliteRepository) Load(ctx context.Context, URL string) (content []byte, contentType string, err error) {
-	content, contentType, err = c.load(ctx, URL)
+// stale returns the stored target regardless of its age
+// along with the validators for a conditional request.
+func (c *sqliteRepository) stale(ctx context.Context, URL string) (response *pageseo.Response, v pageseo.Validators, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err = c.stmtStale.Reset(); err != nil {
+		return nil, v, err
+	}
+	c.stmtStale.BindText(1, URL)
+	var ok bool
+	for {
+		ok, err = c.stmtStale.Step()
+		if err != nil {
+			return nil, v, err
+		}
+		if !ok {
+			break
+		}
+		if response, err = scanResponse(c.stmtStale, URL); err != nil {
+			return nil, v, err
+		}
+		v.ETag = c.stmtStale.ColumnText(7)
+		v.LastModified = c.stmtStale.ColumnText(8)
+	}
+	if response == nil || response.ContentType == "" {
+		return nil, v, os.ErrNotExist
+	}
+	return response, v, nil
+}
+
+// LoadResponse returns the stored target while it is fresh.
+// Expired targets are revalidated with a conditional request,
+// which keeps the stored response when the server responds
+// with HTTP 304 Not Modified.
+func (c *sqliteRepository) LoadResponse(ctx context.Context, URL string) (*pageseo.Response, error) {
+	stored, err := c.load(ctx, URL)
 	if err == nil || !os.IsNotExist(err) {
-		return content, contentType, err
+		return stored, err
 	}
 
-	content, contentType, err = c.Loader.Load(ctx, URL)
+	stored, v, err := c.stale(ctx, URL)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	response, err := pageseo.LoadResponse(pageseo.WithValidators(ctx, URL, v), c.Loader, URL)
+	if response.IsNotModified() && stored != nil {
+		fresh := pageseo.ValidatorsFromHeader(response.Header)
+		if err = c.touch(ctx, URL, fresh); err != nil {
+			return nil, err
+		}
+		return stored, nil
+	}
 	if err != nil {
-		return nil, "", err
+		return response, err
 	}
-	if err = c.push(ctx, URL, contentType, content); err != nil {
+	if err = c.push(ctx, URL, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *sqliteRepository) Load(ctx context.Context, URL string) ([]byte, string, error) {
+	response, err := c.LoadResponse(ctx, URL)
+	if response == nil || err != nil {
 		return nil, "", err
 	}
-	return content, contentType, nil
+	return response.Content, response.ContentType, nil
 }
 
-func (c *sqliteRepository) push(ctx context.Context, URL, contentType string, content []byte) (err error) {
+// touch extends the life of a target that did not change.
+func (c *sqliteRepository) touch(ctx context.Context, URL string, v pageseo.Validators) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err = c.stmtTouch.Reset(); err != nil {
+		return err
+	}
+
+	// updated_at, etag, last_modified, url
+	c.stmtTouch.BindText(1, encodeTime(time.Now()))
+	c.stmtTouch.BindText(2, v.ETag)
+	c.stmtTouch.BindText(3, v.LastModified)
+	c.stmtTouch.BindText(4, URL)
+
+	var ok bool
+	for {
+		ok, err = c.stmtTouch.Step()
+		if err != nil || !ok {
+			break
+		}
+	}
+	return err
+}
+
+func (c *sqliteRepository) push(ctx context.Context, URL string, response *pageseo.Response) (err error) {
+	redirects, err := encodeRedirects(response.Redirects)
+	if err != nil {
+		return err
+	}
+	header := ""
+	if len(response.Header) > 0 {
+		encoded, err := json.Marshal(response.Header)
+		if err != nil {
+			return err
+		}
+		header = string(encoded)
+	}
+	v := pageseo.ValidatorsFromHeader(response.Header)
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	if err = c.stmtPush.Reset(); err != nil {
 		return err
 	}
 
-	// url, content_type, content, created_at, updated_at
+	// url, content_type, content, etag, last_modified, redirects,
+	// status_code, header, content_encoding, truncated, created_at, updated_at
 	t := time.Now()
 	c.stmtPush.BindText(1, URL)
-	c.stmtPush.BindText(2, strings.ToLower(contentType))
-	c.stmtPush.BindBytes(3, content)
-	c.stmtPush.BindText(4, encodeTime(t))
-	c.stmtPush.BindText(5, encodeTime(t))
+	c.stmtPush.BindText(2, strings.ToLower(response.ContentType))
+	c.stmtPush.BindBytes(3, response.Content)
+	c.stmtPush.BindText(4, v.ETag)
+	c.stmtPush.BindText(5, v.LastModified)
+	c.stmtPush.BindText(6, redirects)
+	c.stmtPush.BindInt64(7, int64(response.StatusCode))
+	c.stmtPush.BindText(8, header)
+	c.stmtPush.BindText(9, response.ContentEncoding)
+	c.stmtPush.BindBool(10, response.Truncated)
+	c.stmtPush.BindText(11, encodeTime(t))
+	c.stmtPush.BindText(12, encodeTime(t))
 
 	var ok bool
 	for {
@@ -74,3 +205,57 @@ func (c *sqliteRepository) push(ctx context.Context, URL, contentType string, co
 	}
 	return err
 }
+
+// TraceRedirects returns the redirects stored with the target,
+// which were followed when its content was downloaded. Targets
+// that failed to load are traced by the wrapped loader.
+func (c *sqliteRepository) TraceRedirects(URL string) []pageseo.Redirect {
+	chain, err := c.trace(URL)
+	if err != nil {
+		return pageseo.TraceRedirects(c.Loader, URL)
+	}
+	return chain
+}
+
+func (c *sqliteRepository) trace(URL string) (chain []pageseo.Redirect, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err = c.stmtTrace.Reset(); err != nil {
+		return nil, err
+	}
+	c.stmtTrace.BindText(1, URL)
+	ok, err := c.stmtTrace.Step()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	chain, err = decodeRedirects(c.stmtTrace.ColumnText(0))
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.stmtTrace.Step() // finish the statement
+	return chain, err
+}
+
+func encodeRedirects(chain []pageseo.Redirect) (string, error) {
+	if len(chain) == 0 {
+		return "", nil
+	}
+	encoded, err := json.Marshal(chain)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+func decodeRedirects(encoded string) (chain []pageseo.Redirect, err error) {
+	if encoded == "" {
+		return nil, nil
+	}
+	if err = json.Unmarshal([]byte(encoded), &chain); err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
@@ -21,10 +21,17 @@ type sqliteRepository struct {
 	Loader     pageseo.Loader
 	TimeToLive time.Duration
 
-	stmtPush *sqlite.Stmt
-	stmtMark *sqlite.Stmt
-	stmtPull *sqlite.Stmt
-	stmtNext *sqlite.Stmt
+	stmtPush  *sqlite.Stmt
+	stmtMark  *sqlite.Stmt
+	stmtPull  *sqlite.Stmt
+	stmtNext  *sqlite.Stmt
+	stmtStale *sqlite.Stmt
+	stmtTouch *sqlite.Stmt
+	stmtTrace *sqlite.Stmt
+
+	stmtLinkPush   *sqlite.Stmt
+	stmtLinkList   *sqlite.Stmt
+	stmtLinkForget *sqlite.Stmt
 }
 
 func New(
@@ -36,6 +43,8 @@ func New(
 	if tableName == "" {
 		tableName = "pageseo_cache"
 	}
+	linkTableName := escapeIdentifier(tableName + "_links")
+	rawTableName := tableName
 	tableName = escapeIdentifier(tableName)
 
 	if err = sqlitex.ExecScript(conn, `
@@ -44,13 +53,32 @@ func New(
 			url text NOT NULL UNIQUE,
 			content_type text NOT NULL,
 			content blob NOT NULL,
+			etag text NOT NULL DEFAULT '',
+			last_modified text NOT NULL DEFAULT '',
+			redirects text NOT NULL DEFAULT '',
+			status_code integer NOT NULL DEFAULT 0,
+			header text NOT NULL DEFAULT '',
+			content_encoding text NOT NULL DEFAULT '',
+			truncated integer NOT NULL DEFAULT 0,
 			created_at text NOT NULL,
 			updated_at text NOT NULL,
 			analyzed_at text
 		) STRICT;
+		CREATE TABLE IF NOT EXISTS `+linkTableName+` (
+			id integer PRIMARY KEY,
+			source text NOT NULL,
+			target text NOT NULL,
+			anchor_text text NOT NULL,
+			rel text NOT NULL,
+			created_at text NOT NULL,
+			UNIQUE(source, target, anchor_text)
+		) STRICT;
 	`); err != nil {
 		return nil, err
 	}
+	if err = addMissingColumns(conn, rawTableName); err != nil {
+		return nil, err
+	}
 	if timeToLive < 0 {
 		panic("negative time to live")
 	}
@@ -62,8 +90,8 @@ func New(
 		TimeToLive: timeToLive * -1,
 	}
 	c.stmtPush, err = conn.Prepare(`
-		INSERT INTO ` + tableName + ` (url, content_type, content, created_at, updated_at) VALUES (?, ?, ?, ?, ?)
-		ON CONFLICT(url) DO UPDATE SET content_type=excluded.content_type, content=excluded.content, updated_at=excluded.updated_at
+		INSERT INTO ` + tableName + ` (url, content_type, content, etag, last_modified, redirects, status_code, header, content_encoding, truncated, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(url) DO UPDATE SET content_type=excluded.content_type, content=excluded.content, etag=excluded.etag, last_modified=excluded.last_modified, redirects=excluded.redirects, status_code=excluded.status_code, header=excluded.header, content_encoding=excluded.content_encoding, truncated=excluded.truncated, updated_at=excluded.updated_at
 	`)
 	if err != nil {
 		return nil, err
@@ -75,7 +103,7 @@ func New(
 		return nil, err
 	}
 	c.stmtPull, err = conn.Prepare(`
-		SELECT content_type, content FROM ` + tableName + ` WHERE url=? AND updated_at>?
+		SELECT ` + responseColumns + ` FROM ` + tableName + ` WHERE url=? AND updated_at>?
 	`)
 	if err != nil {
 		return nil, err
@@ -86,10 +114,79 @@ func New(
 	if err != nil {
 		return nil, err
 	}
+	c.stmtStale, err = conn.Prepare(`
+		SELECT ` + responseColumns + `, etag, last_modified FROM ` + tableName + ` WHERE url=?
+	`)
+	if err != nil {
+		return nil, err
+	}
+	c.stmtTouch, err = conn.Prepare(`
+		UPDATE ` + tableName + ` SET updated_at=?, etag=COALESCE(NULLIF(?, ''), etag), last_modified=COALESCE(NULLIF(?, ''), last_modified) WHERE url=?
+	`)
+	if err != nil {
+		return nil, err
+	}
+	c.stmtTrace, err = conn.Prepare(`
+		SELECT redirects FROM ` + tableName + ` WHERE url=?
+	`)
+	if err != nil {
+		return nil, err
+	}
+	c.stmtLinkPush, err = conn.Prepare(`
+		INSERT INTO ` + linkTableName + ` (source, target, anchor_text, rel, created_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(source, target, anchor_text) DO UPDATE SET rel=excluded.rel
+	`)
+	if err != nil {
+		return nil, err
+	}
+	c.stmtLinkList, err = conn.Prepare(`
+		SELECT source, target, anchor_text, rel FROM ` + linkTableName + ` WHERE source LIKE ? ORDER BY id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	c.stmtLinkForget, err = conn.Prepare(`
+		DELETE FROM ` + linkTableName + ` WHERE source=?
+	`)
+	if err != nil {
+		return nil, err
+	}
 
 	return c, nil
 }
 
+// addMissingColumns upgrades tables created before revalidation,
+// redirect tracing, and response details were supported.
+func addMissingColumns(conn *sqlite.Conn, tableName string) error {
+	columns := make(map[string]bool)
+	if err := sqlitex.Execute(conn, `SELECT name FROM pragma_table_info(?)`, &sqlitex.ExecOptions{
+		Args: []any{tableName},
+		ResultFunc: func(stmt *sqlite.Stmt) error {
+			columns[stmt.ColumnText(0)] = true
+			return nil
+		},
+	}); err != nil {
+		return err
+	}
+	for _, column := range [...][2]string{
+		{"etag", "text NOT NULL DEFAULT ''"},
+		{"last_modified", "text NOT NULL DEFAULT ''"},
+		{"redirects", "text NOT NULL DEFAULT ''"},
+		{"status_code", "integer NOT NULL DEFAULT 0"},
+		{"header", "text NOT NULL DEFAULT ''"},
+		{"content_encoding", "text NOT NULL DEFAULT ''"},
+		{"truncated", "integer NOT NULL DEFAULT 0"},
+	} {
+		if columns[column[0]] {
+			continue
+		}
+		if err := sqlitex.ExecuteTransient(conn, `ALTER TABLE `+escapeIdentifier(tableName)+` ADD COLUMN `+column[0]+` `+column[1], nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // escapeIdentifier safely quotes an SQLite table or column name.
 func escapeIdentifier(name string) string {
 	// Double quotes are escaped by doubling them in SQL identifiers
@@ -1,13 +1,18 @@
 package sqlite
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/dkotik/pageseo"
 	"github.com/dkotik/pageseo/crawler/repository"
 	"github.com/dkotik/pageseo/internal"
 	"zombiezen.com/go/sqlite"
+	"zombiezen.com/go/sqlite/sqlitex"
 )
 
 func TestRepository(t *testing.T) {
@@ -31,17 +36,20 @@ func TestRepository(t *testing.T) {
 	// basic store and recover
 	ctx := t.Context()
 	repo := c.(*sqliteRepository)
-	if err = repo.push(ctx, "https://example.com/", "text/html", []byte("<html><body>test</body></html>")); err != nil {
+	if err = repo.push(ctx, "https://example.com/", &pageseo.Response{
+		ContentType: "text/html",
+		Content:     []byte("<html><body>test</body></html>"),
+	}); err != nil {
 		t.Fatal(err)
 	}
-	data, ct, err := repo.load(ctx, "https://example.com/")
+	stored, err := repo.load(ctx, "https://example.com/")
 	if err != nil {
 		t.Fatal(err)
 	}
-	if string(data) != "<html><body>test</body></html>" {
-		t.Fatal("unexpected data:", string(data))
+	if string(stored.Content) != "<html><body>test</body></html>" {
+		t.Fatal("unexpected data:", string(stored.Content))
 	}
-	if ct != "text/html" {
+	if stored.ContentType != "text/html" {
 		t.Fatal("unexpected content type")
 	}
 
@@ -76,3 +84,129 @@ func TestRepository(t *testing.T) {
 		t.Fatal("unexpected number of targets:", len(targets))
 	}
 }
+
+func TestRevalidation(t *testing.T) {
+	full, notModified := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html><body>Lorem ipsum.</body></html>"))
+	}))
+	t.Cleanup(server.Close)
+
+	conn, err := sqlite.OpenConn(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := New(
+		conn,
+		pageseo.NewHTTPClient(server.Client(), nil),
+		"tableName",
+		time.Nanosecond, // every load revalidates
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 3 {
+		data, ct, err := c.Load(t.Context(), server.URL+"/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "<html><body>Lorem ipsum.</body></html>" || ct != "text/html" {
+			t.Fatal("stored content was not kept:", string(data), ct)
+		}
+	}
+	if full != 1 || notModified != 2 {
+		t.Fatalf("expected 1 full and 2 conditional responses, got %d and %d", full, notModified)
+	}
+}
+
+func TestValidatorColumnMigration(t *testing.T) {
+	conn, err := sqlite.OpenConn(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = sqlitex.ExecuteScript(conn, `
+		CREATE TABLE "legacy" (
+			id integer PRIMARY KEY,
+			url text NOT NULL UNIQUE,
+			content_type text NOT NULL,
+			content blob NOT NULL,
+			created_at text NOT NULL,
+			updated_at text NOT NULL,
+			analyzed_at text
+		) STRICT;
+	`, nil); err != nil {
+		t.Fatal(err)
+	}
+	c, err := New(conn, internal.NewMockLoader(func(s string) (string, error) {
+		return "<html></html>", nil
+	}, "text/html"), "legacy", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = c.Load(t.Context(), "https://example.com/"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStoredResponse(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/old", http.RedirectHandler("/new", http.StatusMovedPermanently))
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Robots-Tag", "noindex")
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html><body>Lorem ipsum.</body></html>"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	conn, err := sqlite.OpenConn(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := New(conn, pageseo.NewHTTPClient(server.Client(), nil), "tableName", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = c.Load(t.Context(), server.URL+"/old"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the next run serves the stored target without the network
+	offline, err := New(conn, internal.NewMockLoader(func(s string) (string, error) {
+		return "", errors.New("offline")
+	}, "text/html"), "tableName", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = offline.Load(t.Context(), server.URL+"/old"); err != nil {
+		t.Fatal(err)
+	}
+	chain := pageseo.TraceRedirects(offline, server.URL+"/old")
+	if len(chain) != 1 || chain[0].To != server.URL+"/new" || chain[0].StatusCode != http.StatusMovedPermanently {
+		t.Fatal("stored target lost its redirects:", chain)
+	}
+	if chain = pageseo.TraceRedirects(offline, server.URL+"/new"); len(chain) != 0 {
+		t.Fatal("unexpected redirects for a location that was not loaded:", chain)
+	}
+
+	response, err := pageseo.LoadResponse(t.Context(), offline, server.URL+"/old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !response.Cached || response.StatusCode != http.StatusOK || response.FinalURL != server.URL+"/new" {
+		t.Fatalf("stored response lost its details: %+v", response)
+	}
+	if response.Header.Get("X-Robots-Tag") != "noindex" || len(response.Redirects) != 1 {
+		t.Fatalf("stored response lost its header or redirects: %+v", response)
+	}
+}
@@ -0,0 +1,150 @@
+package pageseo
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+)
+
+// dataURL is a decoded RFC 2397 resource.
+type dataURL struct {
+	MediaType string
+	Charset   string
+	IsBase64  bool
+	Data      []byte
+}
+
+func parseDataURL(location string) (d dataURL, err error) {
+	scheme, rest, ok := strings.Cut(location, ":")
+	if !ok || !strings.EqualFold(scheme, "data") {
+		return d, errors.New("not a data: URL")
+	}
+	header, payload, ok := strings.Cut(rest, ",")
+	if !ok {
+		return d, errors.New("data: URL has no comma separating the payload")
+	}
+
+	header = strings.TrimSpace(header)
+	if i := strings.LastIndexByte(header, ';'); i >= 0 && strings.EqualFold(strings.TrimSpace(header[i+1:]), "base64") {
+		// the token is case-insensitive, like in browsers
+		header = header[:i]
+		d.IsBase64 = true
+	}
+	if header == "" || header[0] == ';' {
+		// RFC 2397 default when the media type is omitted
+		header = "text/plain" + header
+		if !strings.Contains(header, "charset=") {
+			header += ";charset=US-ASCII"
+		}
+	}
+	var params map[string]string
+	d.MediaType, params, err = mime.ParseMediaType(header)
+	if err != nil {
+		return d, fmt.Errorf("unable to parse data: URL media type %q: %w", header, err)
+	}
+	d.Charset = params["charset"]
+
+	unescaped, err := url.PathUnescape(payload)
+	if err != nil {
+		return d, fmt.Errorf("unable to percent-decode data: URL: %w", err)
+	}
+	if !d.IsBase64 {
+		d.Data = []byte(unescaped)
+		return d, nil
+	}
+
+	unescaped = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\n', '\r', '\f':
+			return -1 // white space is allowed inside attributes
+		default:
+			return r
+		}
+	}, unescaped)
+	d.Data, err = base64.StdEncoding.DecodeString(unescaped)
+	if err != nil {
+		d.Data, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(unescaped, "="))
+		if err != nil {
+			return d, fmt.Errorf("unable to decode data: URL base64 payload: %w", err)
+		}
+	}
+	return d, nil
+}
+
+// isDataURL returns true if the location embeds its content.
+func isDataURL(location string) bool {
+	return len(location) > 5 && strings.EqualFold(location[:5], "data:")
+}
+
+// shortDataURL trims the payload from a data: URL
+// to keep log messages readable.
+func shortDataURL(location string) string {
+	header, _, ok := strings.Cut(location, ",")
+	if !ok || len(header) > 64 {
+		return "data:…"
+	}
+	return header + ",…"
+}
+
+var dataURLLoaderSingleton Loader = dataURLLoader{
+	Fallback: skipAllLoadingSingleton,
+}
+
+type dataURLLoader struct {
+	Fallback Loader
+}
+
+// NewDataURLLoader decodes resources embedded into
+// data: URLs and passes all other locations to
+// the fallback [Loader]. If fallback is nil, other
+// locations return [Skip].
+func NewDataURLLoader(fallback Loader) Loader {
+	if fallback == nil {
+		fallback = skipAllLoadingSingleton
+	}
+	return dataURLLoader{
+		Fallback: fallback,
+	}
+}
+
+func (d dataURLLoader) Load(ctx context.Context, location string) ([]byte, string, error) {
+	if !isDataURL(location) {
+		return d.Fallback.Load(ctx, location)
+	}
+	decoded, err := parseDataURL(location)
+	if err != nil {
+		return nil, "", err
+	}
+	return decoded.Data, decoded.MediaType, nil
+}
+
+func (d dataURLLoader) LoadResponse(ctx context.Context, location string) (*Response, error) {
+	if !isDataURL(location) {
+		return LoadResponse(ctx, d.Fallback, location)
+	}
+	decoded, err := parseDataURL(location)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{
+		URL:         location,
+		FinalURL:    location,
+		ContentType: decoded.MediaType,
+		Content:     decoded.Data,
+	}, nil
+}
+
+func (d dataURLLoader) TraceRedirects(location string) []Redirect {
+	if isDataURL(location) {
+		return nil
+	}
+	return TraceRedirects(d.Fallback, location)
+}
+
+func (d dataURLLoader) RecordPageLink(ctx context.Context, link PageLink) error {
+	return RecordPageLink(ctx, d.Fallback, link)
+}
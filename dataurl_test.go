@@ -0,0 +1,77 @@
+package pageseo
+
+import "testing"
+
+func TestParseDataURL(t *testing.T) {
+	tcs := []struct {
+		URL       string
+		MediaType string
+		Charset   string
+		Data      string
+	}{
+		{
+			URL:       "data:,Hello%2C%20World%21",
+			MediaType: "text/plain",
+			Charset:   "US-ASCII",
+			Data:      "Hello, World!",
+		},
+		{
+			URL:       "data:text/plain;charset=utf-8;base64,SGVsbG8sIFdvcmxkIQ==",
+			MediaType: "text/plain",
+			Charset:   "utf-8",
+			Data:      "Hello, World!",
+		},
+		{
+			URL:       "data:image/svg+xml;base64,PHN2Zy8+",
+			MediaType: "image/svg+xml",
+			Data:      "<svg/>",
+		},
+		{
+			URL:       "data:text/plain;base64,SGVsbG8s\n  IFdvcmxkIQ",
+			MediaType: "text/plain",
+			Data:      "Hello, World!",
+		},
+		{
+			URL:       "data:image/svg+xml;BASE64,PHN2Zy8+",
+			MediaType: "image/svg+xml",
+			Data:      "<svg/>",
+		},
+		{
+			URL:       "data:;Base64,SGVsbG8sIFdvcmxkIQ==",
+			MediaType: "text/plain",
+			Charset:   "US-ASCII",
+			Data:      "Hello, World!",
+		},
+		{
+			URL:       "data:image/svg+xml,%3Csvg%2F%3E",
+			MediaType: "image/svg+xml",
+			Data:      "<svg/>",
+		},
+	}
+
+	for _, tc := range tcs {
+		decoded, err := parseDataURL(tc.URL)
+		if err != nil {
+			t.Fatalf("unable to parse %q: %v", tc.URL, err)
+		}
+		if decoded.MediaType != tc.MediaType {
+			t.Fatalf("unexpected media type for %q: %s", tc.URL, decoded.MediaType)
+		}
+		if decoded.Charset != tc.Charset {
+			t.Fatalf("unexpected character set for %q: %s", tc.URL, decoded.Charset)
+		}
+		if string(decoded.Data) != tc.Data {
+			t.Fatalf("unexpected data for %q: %s", tc.URL, decoded.Data)
+		}
+	}
+
+	for _, invalid := range []string{
+		"http://example.com/",
+		"data:image/png;base64",
+		"data:image/png;base64,!!!",
+	} {
+		if _, err := parseDataURL(invalid); err == nil {
+			t.Fatalf("invalid data: URL %q was accepted", invalid)
+		}
+	}
+}
@@ -1,20 +1,38 @@
 package pageseo
 
-import "slices"
+import (
+	"slices"
+	"time"
+)
 
 const (
-	DefaultMinimumTitleLength        = 4
-	DefaultMaximumTitleLength        = 55
-	DefaultMinimumHeadingLength      = DefaultMinimumTitleLength
-	DefaultMaximumHeadingLength      = 70
-	DefaultMinimumDescriptionLength  = 4
-	DefaultMaximumDescriptionLength  = 125
-	DefaultMaximumKeywordsLength     = DefaultMaximumDescriptionLength
-	DefaultMinimumImageAltTextLength = 0
-	DefaultMaximumImageAltTextLength = DefaultMaximumDescriptionLength
-	DefaultMaximumURLLength          = 2048 // older browser constraint
-	DefaultMinimumAnchorTextLength   = 1
-	DefaultMaximumAnchorTextLength   = DefaultMaximumTitleLength * 6
+	DefaultMinimumTitleLength         = 4
+	DefaultMaximumTitleLength         = 55
+	DefaultMinimumHeadingLength       = DefaultMinimumTitleLength
+	DefaultMaximumHeadingLength       = 70
+	DefaultMinimumDescriptionLength   = 4
+	DefaultMaximumDescriptionLength   = 125
+	DefaultMaximumKeywordsLength      = DefaultMaximumDescriptionLength
+	DefaultMinimumImageAltTextLength  = 0
+	DefaultMaximumImageAltTextLength  = DefaultMaximumDescriptionLength
+	DefaultMaximumImageByteSize       = 512 * 1024
+	DefaultMaximumImageInlineByteSize = 4 * 1024
+	DefaultMaximumImageDisplayRatio   = 2.0  // high density screens
+	DefaultMaximumURLLength           = 2048 // older browser constraint
+	DefaultMinimumAnchorTextLength    = 1
+	DefaultMaximumAnchorTextLength    = DefaultMaximumTitleLength * 6
+	DefaultRequestsPerSecond          = 2.0
+	DefaultRequestBurst               = 4
+	DefaultHostConcurrency            = 2
+	DefaultRetryAttempts              = 3
+	DefaultRetryBaseDelay             = 500 * time.Millisecond
+	DefaultRetryMaximumDelay          = 30 * time.Second
+	DefaultMemoryCacheBytes           = 64 * 1024 * 1024
+	DefaultMemoryCacheEntries         = 4096
+	DefaultMaximumResponseBytes       = 32 * 1024 * 1024
+	DefaultMaximumUncompressedBytes   = 1024 // smaller assets do not benefit
+	DefaultIndexPage                  = "index.html"
+	DefaultNotFoundPage               = "404.html"
 )
 
 func DefaultNodeTests() []NodeTester {
@@ -23,11 +41,17 @@ func DefaultNodeTests() []NodeTester {
 		NewHeadingNodeTester(StringConstraints{}),
 		NewTableNodeTester(StringConstraints{}),
 		NewFigureNodeTester(StringConstraints{}),
-		NewAnchorNodeTester(StringConstraints{}),
-		NewImageNodeTester(StringConstraints{}),
+		NewAnchorNodeTesterWithConstraints(AnchorNodeConstraints{}),
+		NewImageNodeTesterWithConstraints(ImageNodeConstraints{}),
 		NewScriptNodeTester(),
 		NewStyleSheetNodeTester(),
 		NewLinkNodeTester(),
+		NewFormNodeTester(),
+		NewLandmarkNodeTester(),
+		NewIFrameNodeTester(),
+		NewEmbedNodeTester(),
+		NewVideoNodeTester(),
+		NewAudioNodeTester(),
 	}
 }
 
@@ -0,0 +1,321 @@
+/*
+Package diskcache provides a [pageseo.Middleware] that keeps
+loaded resources on disk between runs. Bodies are stored once
+by their SHA-256 hash, so identical assets served from
+different locations take up space only once.
+*/
+package diskcache
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dkotik/pageseo"
+)
+
+const indexFileName = "index.jsonl"
+
+// Entry describes a cached location.
+type Entry struct {
+	URL          string    `json:"url"`
+	Hash         string    `json:"hash"`
+	ContentType  string    `json:"contentType"`
+	Size         int64     `json:"size"`
+	FetchedAt    time.Time `json:"fetchedAt"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+
+	StatusCode      int                `json:"statusCode,omitempty"`
+	Header          http.Header        `json:"header,omitempty"`
+	Redirects       []pageseo.Redirect `json:"redirects,omitempty"`
+	ContentEncoding string             `json:"contentEncoding,omitempty"`
+	Truncated       bool               `json:"truncated,omitempty"`
+}
+
+// Validators identify the cached version for conditional requests.
+func (e Entry) Validators() pageseo.Validators {
+	return pageseo.Validators{
+		ETag:         e.ETag,
+		LastModified: e.LastModified,
+	}
+}
+
+// Cache stores resources in a directory. The index of
+// locations is appended to as resources are loaded and
+// compacted when the cache is opened or pruned.
+type Cache struct {
+	Directory string
+
+	// TimeToLive is how long entries are served without
+	// revalidation. Zero never revalidates.
+	TimeToLive time.Duration
+
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+// Open loads the cache index from the directory,
+// creating the directory if it does not exist.
+func Open(directory string, timeToLive time.Duration) (*Cache, error) {
+	if directory == "" {
+		return nil, errors.New("empty cache directory")
+	}
+	if timeToLive < 0 {
+		return nil, errors.New("negative time to live")
+	}
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return nil, fmt.Errorf("unable to create cache directory: %w", err)
+	}
+	c := &Cache{
+		Directory:  directory,
+		TimeToLive: timeToLive,
+		entries:    make(map[string]Entry),
+	}
+	if err := c.readIndex(); err != nil {
+		return nil, err
+	}
+	if err := c.writeIndex(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Cache) readIndex() error {
+	f, err := os.Open(filepath.Join(c.Directory, indexFileName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("unable to open cache index: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 4096), 1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err = json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue // tolerate a line torn by a crash
+		}
+		c.entries[entry.URL] = entry // later lines win
+	}
+	return scanner.Err()
+}
+
+// writeIndex replaces the index with one line per entry.
+func (c *Cache) writeIndex() (err error) {
+	temporary, err := os.CreateTemp(c.Directory, indexFileName+".*")
+	if err != nil {
+		return fmt.Errorf("unable to write cache index: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(temporary.Name())
+		}
+	}()
+	encoder := json.NewEncoder(temporary)
+	for _, URL := range slices.Sorted(maps.Keys(c.entries)) {
+		if err = encoder.Encode(c.entries[URL]); err != nil {
+			return errors.Join(err, temporary.Close())
+		}
+	}
+	if err = temporary.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temporary.Name(), filepath.Join(c.Directory, indexFileName))
+}
+
+func (c *Cache) appendIndex(entry Entry) error {
+	f, err := os.OpenFile(filepath.Join(c.Directory, indexFileName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("unable to update cache index: %w", err)
+	}
+	if err = json.NewEncoder(f).Encode(entry); err != nil {
+		return errors.Join(err, f.Close())
+	}
+	return f.Close()
+}
+
+func (c *Cache) objectPath(hash string) string {
+	return filepath.Join(c.Directory, "objects", hash[:2], hash[2:])
+}
+
+// Entries returns a snapshot of the index sorted by location.
+func (c *Cache) Entries() []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := make([]Entry, 0, len(c.entries))
+	for _, URL := range slices.Sorted(maps.Keys(c.entries)) {
+		entries = append(entries, c.entries[URL])
+	}
+	return entries
+}
+
+// Get returns the cached content of the location.
+func (c *Cache) Get(URL string) (Entry, []byte, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[URL]
+	c.mu.Unlock()
+	if !ok {
+		return entry, nil, fs.ErrNotExist
+	}
+	content, err := os.ReadFile(c.objectPath(entry.Hash))
+	if err != nil {
+		return entry, nil, err
+	}
+	return entry, content, nil
+}
+
+// Put stores the response to the location. Identical content
+// is written to disk only once.
+func (c *Cache) Put(URL string, response *pageseo.Response) (Entry, error) {
+	content := response.Content
+	sum := sha256.Sum256(content)
+	v := pageseo.ValidatorsFromHeader(response.Header)
+	entry := Entry{
+		URL:             URL,
+		Hash:            hex.EncodeToString(sum[:]),
+		ContentType:     response.ContentType,
+		Size:            int64(len(content)),
+		FetchedAt:       time.Now().UTC(),
+		ETag:            v.ETag,
+		LastModified:    v.LastModified,
+		StatusCode:      response.StatusCode,
+		Header:          response.Header,
+		Redirects:       response.Redirects,
+		Truncated:       response.Truncated,
+		ContentEncoding: response.ContentEncoding,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	object := c.objectPath(entry.Hash)
+	if _, err := os.Stat(object); errors.Is(err, fs.ErrNotExist) {
+		if err = writeFileAtomically(object, content); err != nil {
+			return entry, err
+		}
+	}
+	c.entries[URL] = entry
+	return entry, c.appendIndex(entry)
+}
+
+// touch marks a revalidated entry as fresh.
+func (c *Cache) touch(entry Entry, v pageseo.Validators) error {
+	entry.FetchedAt = time.Now().UTC()
+	if v.ETag != "" {
+		entry.ETag = v.ETag
+	}
+	if v.LastModified != "" {
+		entry.LastModified = v.LastModified
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[entry.URL] = entry
+	return c.appendIndex(entry)
+}
+
+func writeFileAtomically(name string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	temporary, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err = temporary.Write(content); err != nil {
+		return errors.Join(err, temporary.Close(), os.Remove(temporary.Name()))
+	}
+	if err = temporary.Close(); err != nil {
+		return errors.Join(err, os.Remove(temporary.Name()))
+	}
+	return os.Rename(temporary.Name(), name)
+}
+
+// WrapLoader serves cached resources and stores successful
+// loads. Expired entries are revalidated with a conditional
+// request when the server provided validators.
+func (c *Cache) WrapLoader(l pageseo.Loader) pageseo.Loader {
+	if l == nil {
+		panic("nil loader")
+	}
+	return loader{Loader: l, cache: c}
+}
+
+type loader struct {
+	Loader pageseo.Loader
+	cache  *Cache
+}
+
+func (l loader) Load(ctx context.Context, URL string) ([]byte, string, error) {
+	response, err := l.LoadResponse(ctx, URL)
+	if response == nil {
+		return nil, "", err
+	}
+	return response.Content, response.ContentType, err
+}
+
+func (l loader) LoadResponse(ctx context.Context, URL string) (*pageseo.Response, error) {
+	entry, content, err := l.cache.Get(URL)
+	cached := err == nil
+	if cached && (l.cache.TimeToLive == 0 || time.Since(entry.FetchedAt) < l.cache.TimeToLive) {
+		return cachedResponse(entry, content), nil
+	}
+
+	if cached {
+		ctx = pageseo.WithValidators(ctx, URL, entry.Validators())
+	}
+	response, err := pageseo.LoadResponse(ctx, l.Loader, URL)
+	if cached && response.IsNotModified() {
+		if err = l.cache.touch(entry, pageseo.ValidatorsFromHeader(response.Header)); err != nil {
+			return nil, err
+		}
+		return cachedResponse(entry, content), nil
+	}
+	if err != nil {
+		return response, err
+	}
+	if _, err = l.cache.Put(URL, response); err != nil {
+		return response, fmt.Errorf("unable to cache <%s>: %w", URL, err)
+	}
+	return response, nil
+}
+
+func (l loader) TraceRedirects(URL string) []pageseo.Redirect {
+	return pageseo.TraceRedirects(l.Loader, URL)
+}
+
+func (l loader) RecordPageLink(ctx context.Context, link pageseo.PageLink) error {
+	return pageseo.RecordPageLink(ctx, l.Loader, link)
+}
+
+func cachedResponse(entry Entry, content []byte) *pageseo.Response {
+	response := &pageseo.Response{
+		URL:             entry.URL,
+		FinalURL:        entry.URL,
+		StatusCode:      entry.StatusCode,
+		Header:          entry.Header,
+		Redirects:       entry.Redirects,
+		ContentType:     entry.ContentType,
+		Content:         content,
+		ContentEncoding: entry.ContentEncoding,
+		Cached:          true,
+		Truncated:       entry.Truncated,
+	}
+	if len(entry.Redirects) > 0 {
+		response.FinalURL = entry.Redirects[len(entry.Redirects)-1].To
+	}
+	return response
+}
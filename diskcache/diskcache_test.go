@@ -0,0 +1,173 @@
+package diskcache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dkotik/pageseo"
+)
+
+func TestCache(t *testing.T) {
+	requests, notModified := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"lorem"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"lorem"`)
+		w.Header().Set("Content-Type", "text/css")
+		_, _ = w.Write([]byte("body { color: black; }"))
+	}))
+	t.Cleanup(server.Close)
+
+	directory := t.TempDir()
+	cache, err := Open(directory, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader := cache.WrapLoader(pageseo.NewHTTPClient(server.Client(), nil))
+	for _, path := range []string{"/a.css", "/b.css", "/a.css"} {
+		data, ct, err := loader.Load(t.Context(), server.URL+path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "body { color: black; }" || ct != "text/css" {
+			t.Fatal("unexpected content:", string(data), ct)
+		}
+	}
+	if requests != 2 {
+		t.Fatal("cached resource was downloaded again:", requests)
+	}
+
+	// survives a restart and revalidates expired entries
+	cache, err = Open(directory, time.Nanosecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := cache.Entries()
+	if len(entries) != 2 || entries[0].Hash != entries[1].Hash {
+		t.Fatal("identical content was not deduplicated:", entries)
+	}
+	loader = cache.WrapLoader(pageseo.NewHTTPClient(server.Client(), nil))
+	if data, _, err := loader.Load(t.Context(), server.URL+"/a.css"); err != nil || len(data) == 0 {
+		t.Fatal("revalidation failed:", err)
+	}
+	if notModified != 1 {
+		t.Fatal("expired entry was not revalidated:", notModified)
+	}
+
+	result, err := cache.Prune(0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RemovedEntries != 2 || result.RemovedObjects != 1 || result.RemainingBytes != 0 {
+		t.Fatalf("unexpected prune result: %+v", result)
+	}
+	if cache, err = Open(directory, 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(cache.Entries()) != 0 {
+		t.Fatal("pruned entries were restored")
+	}
+}
+
+func TestCachedResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+			return
+		}
+		w.Header().Set("X-Robots-Tag", "noindex")
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html><body>Lorem ipsum.</body></html>"))
+	}))
+	t.Cleanup(server.Close)
+
+	directory := t.TempDir()
+	cache, err := Open(directory, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader := cache.WrapLoader(pageseo.NewHTTPClient(server.Client(), nil))
+	if _, err = pageseo.LoadResponse(t.Context(), loader, server.URL+"/old"); err != nil {
+		t.Fatal(err)
+	}
+
+	// a temporary file left by an interrupted write
+	stray := filepath.Join(directory, "objects", "ab", strings.Repeat("c", 62)+".123")
+	if err = os.MkdirAll(filepath.Dir(stray), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(stray, []byte("partial"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cache.Prune(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(stray); err != nil {
+		t.Fatal("prune removed a file that is not a stored object:", err)
+	}
+
+	cache, err = Open(directory, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Close() // served from disk only
+	response, err := pageseo.LoadResponse(t.Context(), cache.WrapLoader(pageseo.NewHTTPClient(http.DefaultClient, nil)), server.URL+"/old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !response.Cached || response.StatusCode != http.StatusOK || response.Header.Get("X-Robots-Tag") != "noindex" {
+		t.Fatalf("response was not restored: %+v", response)
+	}
+	if len(response.Redirects) != 1 || response.FinalURL != server.URL+"/new" {
+		t.Fatal("redirects were not restored:", response.Redirects, response.FinalURL)
+	}
+}
+
+func TestCompressedResponse(t *testing.T) {
+	style := strings.Repeat("body { color: black; }\n", 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var b bytes.Buffer
+		gz := gzip.NewWriter(&b)
+		_, _ = gz.Write([]byte(style))
+		_ = gz.Close()
+		w.Header().Set("Content-Type", "text/css")
+		w.Header().Set("Content-Encoding", "gzip")
+		_, _ = w.Write(b.Bytes())
+	}))
+	t.Cleanup(server.Close)
+
+	directory := t.TempDir()
+	cache, err := Open(directory, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = pageseo.LoadResponse(t.Context(), cache.WrapLoader(pageseo.NewHTTPClient(server.Client(), nil)), server.URL+"/style.css"); err != nil {
+		t.Fatal(err)
+	}
+
+	if cache, err = Open(directory, 0); err != nil {
+		t.Fatal(err)
+	}
+	server.Close() // served from disk only
+	response, err := pageseo.LoadResponse(t.Context(), cache.WrapLoader(pageseo.NewHTTPClient(http.DefaultClient, nil)), server.URL+"/style.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !response.Cached || response.ContentEncoding != "gzip" || response.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("content encoding was not restored: %q %q", response.ContentEncoding, response.Header.Get("Content-Encoding"))
+	}
+	if string(response.Content) != style {
+		t.Fatal("cached content is not decoded:", len(response.Content))
+	}
+}
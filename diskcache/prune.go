@@ -0,0 +1,117 @@
+package diskcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// PruneResult summarizes the removed cache data.
+type PruneResult struct {
+	RemovedEntries int
+	RemovedObjects int
+	FreedBytes     int64
+	RemainingBytes int64
+}
+
+// Prune removes entries fetched longer than maximum age ago,
+// then the oldest entries until the stored content fits within
+// maximum bytes. Zero disables either limit. Content no longer
+// referenced by any entry is deleted.
+func (c *Cache) Prune(maximumAge time.Duration, maximumBytes int64) (result PruneResult, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if maximumAge > 0 {
+		cutoff := time.Now().Add(-maximumAge)
+		for URL, entry := range c.entries {
+			if entry.FetchedAt.Before(cutoff) {
+				delete(c.entries, URL)
+				result.RemovedEntries++
+			}
+		}
+	}
+
+	if maximumBytes > 0 {
+		references := make(map[string]int, len(c.entries))
+		for _, entry := range c.entries {
+			references[entry.Hash]++
+		}
+		total := c.referencedBytes()
+		oldest := slices.SortedFunc(maps.Values(c.entries), func(a, b Entry) int {
+			return a.FetchedAt.Compare(b.FetchedAt)
+		})
+		for _, entry := range oldest {
+			if total <= maximumBytes {
+				break
+			}
+			delete(c.entries, entry.URL)
+			result.RemovedEntries++
+			if references[entry.Hash]--; references[entry.Hash] == 0 {
+				total -= entry.Size
+			}
+		}
+	}
+
+	referenced := make(map[string]bool, len(c.entries))
+	for _, entry := range c.entries {
+		referenced[entry.Hash] = true
+	}
+	if err = filepath.WalkDir(filepath.Join(c.Directory, "objects"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		hash := filepath.Base(filepath.Dir(path)) + d.Name()
+		if referenced[hash] || !isHash(hash) {
+			// leaves temporary files of concurrent writes alone
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if err = os.Remove(path); err != nil {
+			return err
+		}
+		result.RemovedObjects++
+		result.FreedBytes += info.Size()
+		return nil
+	}); err != nil {
+		return result, err
+	}
+	result.RemainingBytes = c.referencedBytes()
+	return result, c.writeIndex()
+}
+
+// referencedBytes counts each stored object once.
+func (c *Cache) referencedBytes() (total int64) {
+	seen := make(map[string]bool, len(c.entries))
+	for _, entry := range c.entries {
+		if !seen[entry.Hash] {
+			seen[entry.Hash] = true
+			total += entry.Size
+		}
+	}
+	return total
+}
+
+// isHash returns true for hexadecimal SHA-256 sums.
+func isHash(name string) bool {
+	if len(name) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(name)
+	return err == nil
+}
@@ -0,0 +1,256 @@
+/*
+Package fixture records the responses of a live
+[pageseo.Loader] into a testdata directory and replays
+them, so that page tests run against snapshots of real
+sites without network access.
+
+Each response is kept in a separate JSON file named after
+the location, which makes snapshot changes easy to review.
+*/
+package fixture
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/dkotik/pageseo"
+)
+
+var record = flag.Bool("pageseo.record", false, "record pageseo fixtures using live loaders")
+
+// ErrUnexpectedRequest is returned by the replaying loader
+// for locations that were not recorded.
+var ErrUnexpectedRequest = errors.New("unexpected request without a recorded fixture")
+
+// Fixture is the file format of a recorded response.
+type Fixture struct {
+	URL         string             `json:"url"`
+	FinalURL    string             `json:"finalURL,omitempty"`
+	StatusCode  int                `json:"statusCode,omitempty"`
+	Header      http.Header        `json:"header,omitempty"`
+	Redirects   []pageseo.Redirect `json:"redirects,omitempty"`
+	ContentType string             `json:"contentType,omitempty"`
+
+	// Text holds valid UTF-8 content.
+	// Other content is kept in Content.
+	Text    string `json:"text,omitempty"`
+	Content []byte `json:"content,omitempty"`
+
+	ContentEncoding string `json:"contentEncoding,omitempty"`
+	TransferSize    int64  `json:"transferSize,omitempty"`
+
+	// Truncated records [pageseo.Response.Truncated].
+	Truncated bool `json:"truncated,omitempty"`
+
+	// Skip records [pageseo.Skip].
+	Skip bool `json:"skip,omitempty"`
+
+	// Error records a failed load, other
+	// than an HTTP status error.
+	Error string `json:"error,omitempty"`
+}
+
+// New records the live loader responses into the directory
+// when tests run with the -pageseo.record flag, and replays
+// them otherwise. The test fails on unexpected requests.
+//
+//	go test ./... -args -pageseo.record
+func New(t testing.TB, directory string, live pageseo.Loader) pageseo.Loader {
+	if *record {
+		return Record(directory, live)
+	}
+	return replayLoader{Directory: directory, TB: t}
+}
+
+// Path returns the fixture file of the location.
+func Path(directory, location string) string {
+	sum := sha256.Sum256([]byte(location))
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, strings.TrimPrefix(strings.TrimPrefix(location, "https://"), "http://"))
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return filepath.Join(directory, name+"-"+hex.EncodeToString(sum[:4])+".json")
+}
+
+// Record wraps the live loader and writes every
+// response into the directory.
+func Record(directory string, live pageseo.Loader) pageseo.Loader {
+	if live == nil {
+		panic("nil loader")
+	}
+	return &recordLoader{Directory: directory, Loader: live}
+}
+
+type recordLoader struct {
+	mu        sync.Mutex
+	Directory string
+	Loader    pageseo.Loader
+}
+
+func (l *recordLoader) Load(ctx context.Context, URL string) ([]byte, string, error) {
+	response, err := l.LoadResponse(ctx, URL)
+	if response == nil {
+		return nil, "", err
+	}
+	return response.Content, response.ContentType, err
+}
+
+func (l *recordLoader) LoadResponse(ctx context.Context, URL string) (*pageseo.Response, error) {
+	response, err := pageseo.LoadResponse(ctx, l.Loader, URL)
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return response, err
+	}
+	f := Fixture{URL: URL}
+	if response != nil {
+		f.FinalURL = response.FinalURL
+		f.StatusCode = response.StatusCode
+		f.Header = response.Header.Clone()
+		f.Header.Del("Date") // keeps snapshots stable
+		f.Redirects = response.Redirects
+		f.ContentType = response.ContentType
+		f.Truncated = response.Truncated
+		f.ContentEncoding = response.ContentEncoding
+		f.TransferSize = response.TransferSize
+		if utf8.Valid(response.Content) {
+			f.Text = string(response.Content)
+		} else {
+			f.Content = response.Content
+		}
+	}
+	var statusError *pageseo.StatusError
+	switch {
+	case err == nil, errors.As(err, &statusError):
+	case errors.Is(err, pageseo.Skip):
+		f.Skip = true
+	default:
+		f.Error = err.Error()
+	}
+	if writeErr := l.write(f); writeErr != nil {
+		return response, errors.Join(err, writeErr)
+	}
+	return response, err
+}
+
+func (l *recordLoader) write(f Fixture) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to encode fixture: %w", err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err = os.MkdirAll(l.Directory, 0o755); err != nil {
+		return fmt.Errorf("unable to create fixture directory: %w", err)
+	}
+	if err = os.WriteFile(Path(l.Directory, f.URL), append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("unable to write fixture: %w", err)
+	}
+	return nil
+}
+
+func (l *recordLoader) TraceRedirects(URL string) []pageseo.Redirect {
+	return pageseo.TraceRedirects(l.Loader, URL)
+}
+
+func (l *recordLoader) RecordPageLink(ctx context.Context, link pageseo.PageLink) error {
+	return pageseo.RecordPageLink(ctx, l.Loader, link)
+}
+
+// Replay serves the responses recorded in the directory
+// and returns [ErrUnexpectedRequest] for other locations.
+func Replay(directory string) pageseo.Loader {
+	return replayLoader{Directory: directory}
+}
+
+type replayLoader struct {
+	Directory string
+
+	// TB, when set, fails the test on unexpected requests.
+	TB testing.TB
+}
+
+func (l replayLoader) Load(ctx context.Context, URL string) ([]byte, string, error) {
+	response, err := l.LoadResponse(ctx, URL)
+	if response == nil {
+		return nil, "", err
+	}
+	return response.Content, response.ContentType, err
+}
+
+func (l replayLoader) LoadResponse(_ context.Context, URL string) (*pageseo.Response, error) {
+	data, err := os.ReadFile(Path(l.Directory, URL))
+	if errors.Is(err, os.ErrNotExist) {
+		err = fmt.Errorf("%w: <%s>", ErrUnexpectedRequest, URL)
+		if l.TB != nil {
+			l.TB.Helper()
+			l.TB.Errorf("%v; run the tests with -pageseo.record to update fixtures in %q", err, l.Directory)
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read fixture: %w", err)
+	}
+	var f Fixture
+	if err = json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("unable to decode fixture of <%s>: %w", URL, err)
+	}
+	if f.URL != URL {
+		return nil, fmt.Errorf("%w: <%s> collides with the fixture of <%s>", ErrUnexpectedRequest, URL, f.URL)
+	}
+	switch {
+	case f.Skip:
+		return nil, pageseo.Skip
+	case f.Error != "":
+		return nil, errors.New(f.Error)
+	}
+
+	response := &pageseo.Response{
+		URL:         f.URL,
+		FinalURL:    f.FinalURL,
+		StatusCode:  f.StatusCode,
+		Header:      f.Header,
+		Redirects:   f.Redirects,
+		ContentType: f.ContentType,
+		Content:     f.Content,
+		Truncated:   f.Truncated,
+
+		ContentEncoding: f.ContentEncoding,
+		TransferSize:    f.TransferSize,
+	}
+	if f.Text != "" {
+		response.Content = []byte(f.Text)
+	}
+	if f.StatusCode != 0 && (f.StatusCode < 200 || f.StatusCode > 299) {
+		return response, &pageseo.StatusError{
+			StatusCode: f.StatusCode,
+			Header:     f.Header,
+		}
+	}
+	return response, nil
+}
+
+func (l replayLoader) TraceRedirects(URL string) []pageseo.Redirect {
+	response, _ := l.LoadResponse(context.Background(), URL)
+	if response == nil {
+		return nil
+	}
+	return response.Redirects
+}
@@ -0,0 +1,74 @@
+package fixture
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dkotik/pageseo"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/new", http.StatusFound)
+		case "/new":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<!DOCTYPE html><html></html>"))
+		case "/pixel.gif":
+			w.Header().Set("Content-Type", "image/gif")
+			_, _ = w.Write([]byte{'G', 'I', 'F', 0xff, 0x00})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	directory := t.TempDir()
+	recording := Record(directory, pageseo.NewHTTPClient(server.Client(), nil))
+	for _, path := range []string{"/old", "/pixel.gif", "/missing"} {
+		_, _, _ = recording.Load(t.Context(), server.URL+path)
+	}
+	server.Close() // replay must not touch the network
+
+	replay := Replay(directory)
+	response, err := pageseo.LoadResponse(t.Context(), replay, server.URL+"/old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.FinalURL != server.URL+"/new" || string(response.Content) != "<!DOCTYPE html><html></html>" {
+		t.Fatal("unexpected response:", response.FinalURL, string(response.Content))
+	}
+	if len(response.Redirects) != 1 || response.Redirects[0].StatusCode != http.StatusFound {
+		t.Fatal("redirects were not recorded:", response.Redirects)
+	}
+	if response.Header.Get("Date") != "" {
+		t.Fatal("volatile header was recorded")
+	}
+
+	data, ct, err := replay.Load(t.Context(), server.URL+"/pixel.gif")
+	if err != nil || ct != "image/gif" || string(data) != "GIF\xff\x00" {
+		t.Fatal("binary content was not replayed:", ct, data, err)
+	}
+
+	var statusError *pageseo.StatusError
+	if _, _, err = replay.Load(t.Context(), server.URL+"/missing"); !errors.As(err, &statusError) || statusError.StatusCode != http.StatusNotFound {
+		t.Fatal("expected recorded status error, got:", err)
+	}
+	if _, _, err = replay.Load(t.Context(), server.URL+"/unknown"); !errors.Is(err, ErrUnexpectedRequest) {
+		t.Fatal("expected unexpected request error, got:", err)
+	}
+}
+
+func TestPath(t *testing.T) {
+	a := Path("testdata", "https://example.com/a?b=c")
+	b := Path("testdata", "https://example.com/a?b=d")
+	if a == b {
+		t.Fatal("locations share a fixture file:", a)
+	}
+	if a != Path("testdata", "https://example.com/a?b=c") {
+		t.Fatal("fixture file is not stable")
+	}
+}
@@ -0,0 +1,61 @@
+package pageseo
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"github.com/dkotik/pageseo/internal"
+	"golang.org/x/net/html"
+)
+
+// isIndicatedByDefault returns true for fragments that
+// browsers resolve without a matching element: the empty
+// fragment and "top" scroll to the top of the document,
+// and text fragments highlight a passage.
+func isIndicatedByDefault(fragment string) bool {
+	return fragment == "" || strings.EqualFold(fragment, "top") || strings.HasPrefix(fragment, ":~:")
+}
+
+// hasFragmentTarget returns true if the document contains
+// an element with a matching [id] or an <a[name]>.
+func hasFragmentTarget(document *html.Node, fragment string) bool {
+	if decoded, err := url.PathUnescape(fragment); err == nil {
+		fragment = decoded
+	}
+	if isIndicatedByDefault(fragment) {
+		return true
+	}
+	for node := range document.Descendants() {
+		if node.Type != html.ElementNode {
+			continue
+		}
+		if id, ok := internal.GetAttribute(node, "id"); ok && id == fragment {
+			return true
+		}
+		if node.Data == "a" {
+			if name, ok := internal.GetAttribute(node, "name"); ok && name == fragment {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasFragmentTargetInHTML parses the document before
+// looking for the fragment target.
+func hasFragmentTargetInHTML(data []byte, fragment string) (bool, error) {
+	document, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return false, err
+	}
+	return hasFragmentTarget(document, fragment), nil
+}
+
+// getDocumentRoot climbs up to the top of the tree.
+func getDocumentRoot(node *html.Node) *html.Node {
+	for node.Parent != nil {
+		node = node.Parent
+	}
+	return node
+}
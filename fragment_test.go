@@ -0,0 +1,37 @@
+package pageseo
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestFragmentTarget(t *testing.T) {
+	document, err := html.Parse(strings.NewReader(`<!DOCTYPE html>
+<html><body>
+	<h2 id="lorem-ipsum">Lorem Ipsum</h2>
+	<a name="legacy"></a>
+	<p name="paragraph">Dolor sit amet.</p>
+	<h2 id="café">Café</h2>
+</body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for fragment, expected := range map[string]bool{
+		"":                true,
+		"top":             true,
+		":~:text=dolor":   true,
+		"lorem-ipsum":     true,
+		"legacy":          true,
+		"caf%C3%A9":       true,
+		"paragraph":       false,
+		"Lorem-Ipsum":     false,
+		"missing-section": false,
+	} {
+		if hasFragmentTarget(document, fragment) != expected {
+			t.Errorf("fragment #%s resolution does not match expected: %v", fragment, expected)
+		}
+	}
+}
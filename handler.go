@@ -0,0 +1,100 @@
+package pageseo
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+)
+
+// NewHandlerLoader serves loads by invoking the handler
+// directly, without a listener. Relative locations are
+// resolved against the base URL. Redirects are followed
+// like [NewHTTPClient] does, while locations on other hosts
+// return [Skip].
+func NewHandlerLoader(handler http.Handler, baseURL string) Loader {
+	if handler == nil {
+		panic("nil HTTP handler")
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil || !base.IsAbs() {
+		panic(fmt.Sprintf("invalid base URL %q", baseURL))
+	}
+	return handlerLoader{
+		Base: base,
+		loaderHTTP: loaderHTTP{
+			Client: &http.Client{
+				Transport: handlerTransport{
+					Handler: handler,
+					Host:    base.Host,
+				},
+			},
+			Redirects: &redirectLog{},
+		},
+	}
+}
+
+type handlerLoader struct {
+	loaderHTTP
+	Base *url.URL
+}
+
+func (h handlerLoader) resolve(URL string) string {
+	resolved, err := h.Base.Parse(URL)
+	if err != nil {
+		return URL // reported by the request
+	}
+	return resolved.String()
+}
+
+func (h handlerLoader) Load(ctx context.Context, URL string) ([]byte, string, error) {
+	return h.loaderHTTP.Load(ctx, h.resolve(URL))
+}
+
+func (h handlerLoader) LoadResponse(ctx context.Context, URL string) (*Response, error) {
+	return h.loaderHTTP.LoadResponse(ctx, h.resolve(URL))
+}
+
+func (h handlerLoader) TraceRedirects(URL string) []Redirect {
+	return h.loaderHTTP.TraceRedirects(h.resolve(URL))
+}
+
+// handlerTransport records handler responses
+// the way [http.Server] would send them.
+type handlerTransport struct {
+	Handler http.Handler
+	Host    string
+}
+
+func (h handlerTransport) RoundTrip(req *http.Request) (response *http.Response, err error) {
+	if req.URL.Host != h.Host {
+		return nil, fmt.Errorf("%w: <%s> is not served by the handler", Skip, req.URL)
+	}
+	served := req.Clone(req.Context())
+	served.Host = req.URL.Host
+	served.RequestURI = req.URL.RequestURI()
+	served.RemoteAddr = "192.0.2.1:1234"
+	if served.Body == nil {
+		served.Body = http.NoBody
+	}
+	if req.URL.Scheme == "https" {
+		served.TLS = &tls.ConnectionState{ServerName: req.URL.Hostname()}
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			if recovered == http.ErrAbortHandler {
+				err = fmt.Errorf("handler aborted <%s>", req.URL)
+				return
+			}
+			err = fmt.Errorf("handler panicked on <%s>: %v", req.URL, recovered)
+		}
+	}()
+	recorder := httptest.NewRecorder()
+	h.Handler.ServeHTTP(recorder, served)
+	response = recorder.Result()
+	response.Request = req
+	return response, nil
+}
@@ -0,0 +1,61 @@
+package pageseo
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestHandlerLoader(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Robots-Tag", "noindex")
+		_, _ = w.Write([]byte("<!DOCTYPE html><html></html>"))
+	})
+	mux.HandleFunc("/away", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://example.org/", http.StatusFound)
+	})
+	mux.HandleFunc("/secure", func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || r.Host != "example.com" {
+			http.Error(w, "insecure", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte("secure"))
+	})
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	loader := NewHandlerLoader(mux, "https://example.com/")
+
+	response, err := LoadResponse(t.Context(), loader, "/old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.FinalURL != "https://example.com/new" || response.ContentType != "text/html" {
+		t.Fatal("unexpected response:", response.FinalURL, response.ContentType)
+	}
+	if !isExcludedFromIndex(response.Header) {
+		t.Fatal("headers were not preserved:", response.Header)
+	}
+	if chain := TraceRedirects(loader, "https://example.com/old"); len(chain) != 1 || chain[0].StatusCode != http.StatusMovedPermanently {
+		t.Fatal("redirects were not traced:", chain)
+	}
+
+	if _, _, err = loader.Load(t.Context(), "/secure"); err != nil {
+		t.Fatal("request does not look like it arrived over TLS:", err)
+	}
+
+	var statusError *StatusError
+	if _, _, err = loader.Load(t.Context(), "/missing"); !errors.As(err, &statusError) || statusError.StatusCode != http.StatusNotFound {
+		t.Fatal("expected status error, got:", err)
+	}
+	if _, _, err = loader.Load(t.Context(), "/away"); !errors.Is(err, Skip) {
+		t.Fatal("expected other hosts to be skipped, got:", err)
+	}
+	if _, _, err = loader.Load(t.Context(), "/panic"); err == nil {
+		t.Fatal("handler panic was not reported")
+	}
+}
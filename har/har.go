@@ -0,0 +1,250 @@
+/*
+Package har replays HTTP Archive 1.2 files, as exported by
+browser developer tools, through a [pageseo.Loader].
+*/
+package har
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dkotik/pageseo"
+)
+
+// ErrNotRecorded is returned by [FailOnMiss] for
+// locations that are missing from the archive.
+var ErrNotRecorded = errors.New("location is not recorded in the archive")
+
+// FailOnMiss is a fallback [pageseo.Loader] that
+// rejects every location with [ErrNotRecorded].
+var FailOnMiss pageseo.Loader = failOnMiss{}
+
+type failOnMiss struct{}
+
+func (failOnMiss) Load(_ context.Context, URL string) ([]byte, string, error) {
+	return nil, "", fmt.Errorf("%w: <%s>", ErrNotRecorded, URL)
+}
+
+// Archive is the root of a HAR file.
+type Archive struct {
+	Log struct {
+		Version string  `json:"version"`
+		Entries []Entry `json:"entries"`
+	} `json:"log"`
+}
+
+// Entry is a recorded request and response pair.
+type Entry struct {
+	StartedDateTime time.Time `json:"startedDateTime"`
+	Time            float64   `json:"time"`
+	Request         struct {
+		Method string `json:"method"`
+		URL    string `json:"url"`
+	} `json:"request"`
+	Response struct {
+		Status      int      `json:"status"`
+		Headers     []Header `json:"headers"`
+		RedirectURL string   `json:"redirectURL"`
+		Content     struct {
+			Size     int64  `json:"size"`
+			MimeType string `json:"mimeType"`
+			Text     string `json:"text"`
+			Encoding string `json:"encoding"`
+		} `json:"content"`
+	} `json:"response"`
+}
+
+// Header is a recorded HTTP header.
+type Header struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Open reads the HAR file, see [New].
+func Open(path string, fallback pageseo.Loader) (pageseo.Loader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open HAR file: %w", err)
+	}
+	defer f.Close()
+	return New(f, fallback)
+}
+
+// New serves the GET responses recorded in the archive with
+// their status codes, headers, and bodies. Locations missing
+// from the archive are passed to the fallback, which may be
+// [FailOnMiss] or another loader. A nil fallback returns
+// [pageseo.Skip] for them.
+func New(r io.Reader, fallback pageseo.Loader) (pageseo.Loader, error) {
+	var archive Archive
+	if err := json.NewDecoder(r).Decode(&archive); err != nil {
+		return nil, fmt.Errorf("unable to decode HAR file: %w", err)
+	}
+	if archive.Log.Version != "" && !strings.HasPrefix(archive.Log.Version, "1.") {
+		return nil, fmt.Errorf("unsupported HAR version %q", archive.Log.Version)
+	}
+
+	entries := make(map[string]Entry, len(archive.Log.Entries))
+	for _, entry := range archive.Log.Entries {
+		if !strings.EqualFold(entry.Request.Method, http.MethodGet) {
+			continue
+		}
+		switch entry.Response.Status {
+		case 0, http.StatusNotModified:
+			// blocked, aborted, or served from the browser cache
+			if _, ok := entries[normalize(entry.Request.URL)]; ok {
+				continue
+			}
+		}
+		entries[normalize(entry.Request.URL)] = entry // later entries win
+	}
+	return &loader{
+		Entries:  entries,
+		Fallback: fallback,
+	}, nil
+}
+
+func normalize(location string) string {
+	location, _, _ = strings.Cut(location, "#")
+	return location
+}
+
+type loader struct {
+	Entries  map[string]Entry
+	Fallback pageseo.Loader
+}
+
+func (l *loader) Load(ctx context.Context, URL string) ([]byte, string, error) {
+	response, err := l.LoadResponse(ctx, URL)
+	if response == nil {
+		return nil, "", err
+	}
+	return response.Content, response.ContentType, err
+}
+
+// maximumRedirects matches the limit of [http.Client].
+const maximumRedirects = 10
+
+// LoadResponse follows recorded redirects the way the
+// browser did and returns the final recorded response.
+func (l *loader) LoadResponse(ctx context.Context, URL string) (*pageseo.Response, error) {
+	entry, ok := l.Entries[normalize(URL)]
+	if !ok {
+		if l.Fallback == nil {
+			return nil, pageseo.Skip
+		}
+		return pageseo.LoadResponse(ctx, l.Fallback, URL)
+	}
+
+	var redirects []pageseo.Redirect
+	duration := time.Duration(entry.Time * float64(time.Millisecond))
+	for entry.Response.Status >= 300 && entry.Response.Status < 400 && entry.Response.RedirectURL != "" {
+		from := entry.Request.URL
+		to := resolve(from, entry.Response.RedirectURL)
+		redirects = append(redirects, pageseo.Redirect{
+			From:       from,
+			To:         to,
+			StatusCode: entry.Response.Status,
+		})
+		if len(redirects) > maximumRedirects {
+			return nil, fmt.Errorf("stopped after %d recorded redirects from <%s>", maximumRedirects, URL)
+		}
+		next, ok := l.Entries[normalize(to)]
+		if !ok {
+			break // the browser did not follow
+		}
+		entry = next
+		duration += time.Duration(entry.Time * float64(time.Millisecond))
+	}
+
+	response, err := decode(entry)
+	if err != nil {
+		return nil, err
+	}
+	response.URL = URL
+	response.Redirects = redirects
+	response.Duration = duration
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response, &pageseo.StatusError{
+			StatusCode: response.StatusCode,
+			Header:     response.Header,
+		}
+	}
+	return response, nil
+}
+
+func (l *loader) TraceRedirects(URL string) []pageseo.Redirect {
+	response, _ := l.LoadResponse(context.Background(), URL)
+	if response == nil {
+		return nil
+	}
+	return response.Redirects
+}
+
+// RecordPageLink passes the link to the fallback loader.
+func (l *loader) RecordPageLink(ctx context.Context, link pageseo.PageLink) error {
+	if l.Fallback == nil {
+		return nil
+	}
+	return pageseo.RecordPageLink(ctx, l.Fallback, link)
+}
+
+func resolve(base, location string) string {
+	b, err := url.Parse(base)
+	if err != nil {
+		return location
+	}
+	resolved, err := b.Parse(location)
+	if err != nil {
+		return location
+	}
+	return resolved.String()
+}
+
+func decode(entry Entry) (*pageseo.Response, error) {
+	header := make(http.Header, len(entry.Response.Headers))
+	for _, h := range entry.Response.Headers {
+		if strings.HasPrefix(h.Name, ":") {
+			continue // HTTP/2 pseudo header
+		}
+		header.Add(h.Name, h.Value)
+	}
+
+	content := []byte(entry.Response.Content.Text)
+	if strings.EqualFold(entry.Response.Content.Encoding, "base64") {
+		var err error
+		if content, err = base64.StdEncoding.DecodeString(entry.Response.Content.Text); err != nil {
+			return nil, fmt.Errorf("unable to decode recorded content of <%s>: %w", entry.Request.URL, err)
+		}
+	}
+
+	contentType := entry.Response.Content.MimeType
+	if contentType == "" {
+		contentType = header.Get("Content-Type")
+	}
+	if contentType != "" {
+		parsed, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse recorded content type %q: %w", contentType, err)
+		}
+		contentType = parsed
+	}
+	return &pageseo.Response{
+		FinalURL:        entry.Request.URL,
+		StatusCode:      entry.Response.Status,
+		Header:          header,
+		ContentType:     contentType,
+		Content:         content,
+		ContentEncoding: pageseo.ContentEncoding(header), // content is recorded decoded
+	}, nil
+}
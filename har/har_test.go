@@ -0,0 +1,91 @@
+package har
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/dkotik/pageseo"
+)
+
+func TestReplay(t *testing.T) {
+	loader, err := Open("testdata/example.har", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := pageseo.LoadResponse(t.Context(), loader, "http://example.com/#top")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.FinalURL != "https://example.com/" || response.ContentType != "text/html" {
+		t.Fatal("unexpected response:", response.FinalURL, response.ContentType)
+	}
+	if len(response.Redirects) != 1 || response.Redirects[0].StatusCode != http.StatusMovedPermanently {
+		t.Fatal("recorded redirect was not followed:", response.Redirects)
+	}
+	if response.Header.Get("X-Robots-Tag") != "noarchive" || response.Header.Get(":status") != "" {
+		t.Fatal("unexpected headers:", response.Header)
+	}
+	if chain := pageseo.TraceRedirects(loader, "http://example.com/"); len(chain) != 1 {
+		t.Fatal("redirects are not traced:", chain)
+	}
+
+	data, ct, err := loader.Load(t.Context(), "https://example.com/pixel.gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct != "image/gif" || len(data) != 42 || string(data[:6]) != "GIF89a" {
+		t.Fatal("base64 content was not decoded:", ct, len(data))
+	}
+
+	response, err = pageseo.LoadResponse(t.Context(), loader, "https://example.com/style.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.ContentEncoding != "gzip, br" || string(response.Content) != "body { color: black; }\n" {
+		t.Fatalf("recorded content encoding was not normalized: %q %q", response.ContentEncoding, response.Content)
+	}
+
+	_, _, err = loader.Load(t.Context(), "https://example.com/missing")
+	var statusError *pageseo.StatusError
+	if !errors.As(err, &statusError) || statusError.StatusCode != http.StatusNotFound {
+		t.Fatal("expected recorded status error, got:", err)
+	}
+}
+
+func TestMisses(t *testing.T) {
+	const location = "https://example.com/unknown"
+
+	skipping, err := Open("testdata/example.har", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = skipping.Load(t.Context(), location); !errors.Is(err, pageseo.Skip) {
+		t.Fatal("expected skip, got:", err)
+	}
+
+	failing, err := Open("testdata/example.har", FailOnMiss)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = failing.Load(t.Context(), location); !errors.Is(err, ErrNotRecorded) {
+		t.Fatal("expected miss error, got:", err)
+	}
+
+	falling, err := Open("testdata/example.har", fallback{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _, err := falling.Load(t.Context(), location)
+	if err != nil || string(data) != location {
+		t.Fatal("miss did not fall through:", string(data), err)
+	}
+}
+
+type fallback struct{}
+
+func (fallback) Load(_ context.Context, URL string) ([]byte, string, error) {
+	return []byte(URL), "text/plain", nil
+}
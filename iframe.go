@@ -0,0 +1,264 @@
+package pageseo
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/dkotik/pageseo/internal"
+	"golang.org/x/net/html"
+)
+
+func NewIFrameNodeTester() NodeTester {
+	return iframe{}
+}
+
+type iframe struct{}
+
+func (f iframe) Match(t testing.TB, node *html.Node) bool {
+	return node.Type == html.ElementNode && node.Data == "iframe"
+}
+
+func (f iframe) ListResourcesForPreloading(origin *url.URL, node *html.Node) (URLs []string) {
+	src, ok := internal.GetAttribute(node, "src")
+	if ok && strings.TrimSpace(src) != "" && !isDataURL(src) {
+		URLs = append(URLs, joinRelativePath(origin, src))
+	}
+	return URLs
+}
+
+func (f iframe) TestNode(t testing.TB, origin *url.URL, node *html.Node, loader Loader) {
+	attributes := internal.GetAttributes(t, node)
+	title, ok := attributes["title"]
+	if !ok || strings.TrimSpace(title) == "" {
+		if ariaLabel := strings.TrimSpace(attributes["aria-label"]); ariaLabel == "" {
+			t.Error("add <iframe[title]> attribute describing the embedded content")
+		}
+	}
+	if loading, ok := attributes["loading"]; !ok || strings.ToLower(loading) != "lazy" {
+		t.Log(internal.WP, "add loading=\"lazy\" attribute to defer loading the <iframe>")
+	}
+
+	src, ok := attributes["src"]
+	if !ok || strings.TrimSpace(src) == "" {
+		if _, ok = attributes["srcdoc"]; !ok {
+			t.Error("missing <iframe[src]> attribute")
+		}
+		return
+	}
+	switch strings.ToLower(strings.TrimSpace(src)) {
+	case "about:blank", "javascript:void(0)", "javascript:;":
+		t.Log(internal.WP, "<iframe[src]> is populated by a script, which search engines may not index")
+		return
+	}
+	if isDataURL(src) {
+		t.Log(internal.WP, "<iframe[src]> embeds a data: URL, which is blocked by most browsers for top level navigation")
+		return
+	}
+
+	location := joinRelativePath(origin, src)
+	validateActiveContent(t, origin, "<iframe[src]>", location)
+	response, err := LoadResponse(t.Context(), loader, location)
+	if err != nil {
+		if errors.Is(err, Skip) {
+			return
+		}
+		t.Errorf("unable to load iframe %q: %v", src, err)
+	}
+	var frame []byte
+	var contentType string
+	if response != nil {
+		frame, contentType = response.Content, response.ContentType
+		validateFrameAncestors(t, origin, response)
+	}
+
+	switch contentType {
+	case "":
+		t.Error("empty Content-Type for the iframe document")
+	case "text/html", "application/xhtml+xml", "application/pdf", "image/svg+xml":
+	default:
+		t.Log("strange iframe Content-Type:", contentType)
+	}
+
+	if len(frame) == 0 {
+		t.Error("empty iframe document")
+	}
+}
+
+// validateFrameAncestors reports frame documents that forbid
+// embedding into the page with the Content Security Policy
+// frame-ancestors directive or the X-Frame-Options header.
+func validateFrameAncestors(t testing.TB, origin *url.URL, response *Response) {
+	if origin == nil || origin.Host == "" {
+		return // page location is unknown
+	}
+	location := response.FinalURL
+	if location == "" {
+		location = response.URL
+	}
+	frame, err := url.Parse(location)
+	if err != nil {
+		return
+	}
+	if policies := getFrameAncestors(response.Header); len(policies) > 0 {
+		for _, sources := range policies {
+			if !slices.ContainsFunc(sources, func(source string) bool {
+				return matchFrameSource(source, origin, frame)
+			}) {
+				t.Errorf("iframe %q forbids embedding into %q with Content-Security-Policy frame-ancestors %q", location, origin.String(), strings.Join(sources, " "))
+				return
+			}
+		}
+		return // frame-ancestors overrides X-Frame-Options
+	}
+
+	switch option := strings.ToUpper(strings.TrimSpace(response.Header.Get("X-Frame-Options"))); {
+	case option == "":
+	case option == "DENY":
+		t.Errorf("iframe %q forbids embedding with X-Frame-Options: DENY", location)
+	case option == "SAMEORIGIN":
+		if !isSameOrigin(origin, frame) {
+			t.Errorf("iframe %q forbids embedding into %q with X-Frame-Options: SAMEORIGIN", location, origin.String())
+		}
+	case strings.HasPrefix(option, "ALLOW-FROM"):
+		t.Log(internal.WP, "iframe X-Frame-Options: ALLOW-FROM is ignored by browsers; use Content-Security-Policy frame-ancestors")
+	default:
+		t.Logf("%s iframe X-Frame-Options %q is not valid", internal.WP, option)
+	}
+}
+
+// getFrameAncestors returns the frame-ancestors source list
+// of each enforced Content Security Policy.
+func getFrameAncestors(header http.Header) (policies [][]string) {
+	for _, policy := range header.Values("Content-Security-Policy") {
+		for directive := range strings.SplitSeq(policy, ";") {
+			fields := strings.Fields(directive)
+			if len(fields) > 0 && strings.EqualFold(fields[0], "frame-ancestors") {
+				policies = append(policies, fields[1:])
+				break // browsers ignore repeated directives
+			}
+		}
+	}
+	return policies
+}
+
+// matchFrameSource returns true if a frame-ancestors
+// source expression allows the page to embed the frame.
+func matchFrameSource(source string, page, frame *url.URL) bool {
+	switch source = strings.ToLower(source); source {
+	case "'none'":
+		return false
+	case "'self'":
+		return isSameOrigin(page, frame)
+	case "*":
+		return page.Scheme == "http" || page.Scheme == "https"
+	}
+	if scheme, ok := strings.CutSuffix(source, ":"); ok {
+		return strings.EqualFold(page.Scheme, scheme)
+	}
+
+	scheme, host, ok := strings.Cut(source, "://")
+	if ok {
+		if !strings.EqualFold(page.Scheme, scheme) && !(scheme == "http" && strings.EqualFold(page.Scheme, "https")) {
+			return false
+		}
+	} else {
+		host = scheme
+		if page.Scheme != "http" && page.Scheme != "https" {
+			return false
+		}
+	}
+	host, _, _ = strings.Cut(host, "/")
+	host, port, hasPort := strings.Cut(host, ":")
+	if hasPort && port != "*" {
+		pagePort := page.Port()
+		if pagePort == "" {
+			pagePort = defaultPort(page.Scheme)
+		}
+		if port != pagePort {
+			return false
+		}
+	} else if !hasPort && page.Port() != "" && page.Port() != defaultPort(page.Scheme) {
+		return false
+	}
+	pageHost := strings.ToLower(page.Hostname())
+	if wildcard, ok := strings.CutPrefix(host, "*."); ok {
+		return strings.HasSuffix(pageHost, "."+wildcard)
+	}
+	return pageHost == host
+}
+
+func isSameOrigin(a, b *url.URL) bool {
+	if !strings.EqualFold(a.Scheme, b.Scheme) || !strings.EqualFold(a.Hostname(), b.Hostname()) {
+		return false
+	}
+	portA, portB := a.Port(), b.Port()
+	if portA == "" {
+		portA = defaultPort(a.Scheme)
+	}
+	if portB == "" {
+		portB = defaultPort(b.Scheme)
+	}
+	return portA == portB
+}
+
+func defaultPort(scheme string) string {
+	switch strings.ToLower(scheme) {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	default:
+		return ""
+	}
+}
+
+func NewEmbedNodeTester() NodeTester {
+	return embed{}
+}
+
+type embed struct{}
+
+func (e embed) Match(t testing.TB, node *html.Node) bool {
+	if node.Type != html.ElementNode {
+		return false
+	}
+	return node.Data == "embed" || node.Data == "object"
+}
+
+func (e embed) ListResourcesForPreloading(origin *url.URL, node *html.Node) []string {
+	return nil
+}
+
+func (e embed) TestNode(t testing.TB, origin *url.URL, node *html.Node, loader Loader) {
+	attributes := internal.GetAttributes(t, node)
+	source := "src"
+	if node.Data == "object" {
+		source = "data"
+	}
+	if src, ok := attributes[source]; !ok || strings.TrimSpace(src) == "" {
+		t.Errorf("missing <%s[%s]> attribute", node.Data, source)
+	} else {
+		validateActiveContent(t, origin, "<"+node.Data+"["+source+"]>", joinRelativePath(origin, strings.TrimSpace(src)))
+	}
+
+	contentType := strings.ToLower(strings.TrimSpace(attributes["type"]))
+	switch contentType {
+	case "":
+		t.Errorf("missing <%s[type]> attribute", node.Data)
+	case "application/x-shockwave-flash", "application/java", "application/x-java-applet":
+		t.Errorf("<%s> plugin content %q is not supported by browsers", node.Data, contentType)
+	}
+
+	if strings.TrimSpace(attributes["title"]) == "" && strings.TrimSpace(attributes["aria-label"]) == "" {
+		if node.Data == "embed" || internal.GetAndTrimText(node) == "" {
+			t.Errorf("add <%s[title]> attribute describing the embedded content", node.Data)
+		}
+	}
+	if node.Data == "embed" {
+		t.Log(internal.WP, "prefer <iframe>, <video>, <audio>, or <img> to <embed>")
+	}
+}
@@ -0,0 +1,191 @@
+package pageseo
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestIFrameNodeTester(t *testing.T) {
+	frame := func(header ...string) *Response {
+		h := make(http.Header)
+		for i := 0; i+1 < len(header); i += 2 {
+			h.Add(header[i], header[i+1])
+		}
+		return &Response{
+			StatusCode:  http.StatusOK,
+			Header:      h,
+			ContentType: "text/html",
+			Content:     []byte("<!DOCTYPE html><title>Widget</title>"),
+		}
+	}
+	const (
+		sameOrigin  = `<iframe src="/widget" title="Widget" loading="lazy"></iframe>`
+		crossOrigin = `<iframe src="https://widgets.example.net/widget" title="Widget" loading="lazy"></iframe>`
+	)
+	cases := []struct {
+		Name   string
+		Body   string
+		Frame  *Response
+		Errors []string
+		Logs   []string
+		Clean  bool
+	}{
+		{
+			Name:  "embeddable frame",
+			Body:  crossOrigin,
+			Frame: frame(),
+			Clean: true,
+		},
+		{
+			Name:   "missing title",
+			Body:   `<iframe src="https://widgets.example.net/widget" loading="lazy"></iframe>`,
+			Frame:  frame(),
+			Errors: []string{"add <iframe[title]> attribute"},
+		},
+		{
+			Name:  "eager loading",
+			Body:  `<iframe src="https://widgets.example.net/widget" title="Widget"></iframe>`,
+			Frame: frame(),
+			Logs:  []string{`add loading="lazy" attribute`},
+		},
+		{
+			Name:   "mixed content",
+			Body:   `<iframe src="http://widgets.example.net/widget" title="Widget" loading="lazy"></iframe>`,
+			Errors: []string{"active mixed content <iframe[src]>"},
+		},
+		{
+			Name:   "empty document",
+			Body:   crossOrigin,
+			Frame:  &Response{ContentType: "text/html"},
+			Errors: []string{"empty iframe document"},
+		},
+		{
+			Name:   "denied by X-Frame-Options",
+			Body:   sameOrigin,
+			Frame:  frame("X-Frame-Options", "DENY"),
+			Errors: []string{"forbids embedding with X-Frame-Options: DENY"},
+		},
+		{
+			Name:   "cross origin denied by X-Frame-Options",
+			Body:   crossOrigin,
+			Frame:  frame("X-Frame-Options", "sameorigin"),
+			Errors: []string{"forbids embedding into \"https://www.example.com/page\" with X-Frame-Options: SAMEORIGIN"},
+		},
+		{
+			Name:  "same origin allowed by X-Frame-Options",
+			Body:  sameOrigin,
+			Frame: frame("X-Frame-Options", "SAMEORIGIN"),
+			Clean: true,
+		},
+		{
+			Name:  "deprecated X-Frame-Options",
+			Body:  crossOrigin,
+			Frame: frame("X-Frame-Options", "ALLOW-FROM https://www.example.com"),
+			Logs:  []string{"ALLOW-FROM is ignored by browsers"},
+		},
+		{
+			Name:   "denied by frame-ancestors",
+			Body:   sameOrigin,
+			Frame:  frame("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'"),
+			Errors: []string{"with Content-Security-Policy frame-ancestors \"'none'\""},
+		},
+		{
+			Name:   "cross origin denied by frame-ancestors",
+			Body:   crossOrigin,
+			Frame:  frame("Content-Security-Policy", "frame-ancestors 'self' https://partner.example.org"),
+			Errors: []string{"with Content-Security-Policy frame-ancestors"},
+		},
+		{
+			Name:  "allowed by frame-ancestors host",
+			Body:  crossOrigin,
+			Frame: frame("Content-Security-Policy", "frame-ancestors https://*.example.com"),
+			Clean: true,
+		},
+		{
+			Name:   "denied by one of several policies",
+			Body:   crossOrigin,
+			Frame:  frame("Content-Security-Policy", "frame-ancestors *", "Content-Security-Policy", "frame-ancestors www.example.com:8443"),
+			Errors: []string{"with Content-Security-Policy frame-ancestors \"www.example.com:8443\""},
+		},
+		{
+			Name:  "frame-ancestors overrides X-Frame-Options",
+			Body:  crossOrigin,
+			Frame: frame("X-Frame-Options", "DENY", "Content-Security-Policy", "frame-ancestors *"),
+			Clean: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			loader := responseMapLoader{}
+			if c.Frame != nil {
+				loader["https://www.example.com/widget"] = c.Frame
+				loader["https://widgets.example.net/widget"] = c.Frame
+			}
+			r := testNodes(t, NewIFrameNodeTester(), "https://www.example.com/page",
+				"<!DOCTYPE html><html><body>"+c.Body+"</body></html>", loader)
+			if c.Clean && (len(r.Errors) > 0 || len(r.Logs) > 0) {
+				t.Errorf("unexpected reports: %q %q", r.Errors, r.Logs)
+			}
+			for _, e := range c.Errors {
+				if !r.HasError(e) {
+					t.Errorf("missing error %q in %q", e, r.Errors)
+				}
+			}
+			for _, l := range c.Logs {
+				if !r.HasLog(l) {
+					t.Errorf("missing log %q in %q", l, r.Logs)
+				}
+			}
+		})
+	}
+}
+
+func TestEmbedNodeTester(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Body   string
+		Errors []string
+	}{
+		{
+			Name:   "missing source and type",
+			Body:   `<embed title="Document">`,
+			Errors: []string{"missing <embed[src]> attribute", "missing <embed[type]> attribute"},
+		},
+		{
+			Name:   "plugin content",
+			Body:   `<object data="/movie.swf" type="application/x-shockwave-flash">Movie</object>`,
+			Errors: []string{`<object> plugin content "application/x-shockwave-flash" is not supported`},
+		},
+		{
+			Name:   "missing title",
+			Body:   `<embed src="/document.pdf" type="application/pdf">`,
+			Errors: []string{"add <embed[title]> attribute"},
+		},
+		{
+			Name:   "mixed content",
+			Body:   `<object data="http://www.example.com/document.pdf" type="application/pdf">Document</object>`,
+			Errors: []string{"active mixed content <object[data]>"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			r := testNodes(t, NewEmbedNodeTester(), "https://www.example.com/",
+				"<!DOCTYPE html><html><body>"+c.Body+"</body></html>", nil)
+			for _, e := range c.Errors {
+				if !r.HasError(e) {
+					t.Errorf("missing error %q in %q", e, r.Errors)
+				}
+			}
+		})
+	}
+
+	r := testNodes(t, NewEmbedNodeTester(), "https://www.example.com/",
+		`<!DOCTYPE html><html><body><object data="/document.pdf" type="application/pdf">Annual report</object></body></html>`, nil)
+	if len(r.Errors) > 0 || len(r.Logs) > 0 {
+		t.Errorf("unexpected reports for a described <object>: %q %q", r.Errors, r.Logs)
+	}
+	if r = testNodes(t, NewEmbedNodeTester(), "https://www.example.com/",
+		`<!DOCTYPE html><html><body><embed src="/document.pdf" type="application/pdf" title="Report"></body></html>`, nil); !r.HasLog("prefer <iframe>") {
+		t.Errorf("missing <embed> hint in %q", r.Logs)
+	}
+}
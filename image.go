@@ -2,8 +2,10 @@ package pageseo
 
 import (
 	"errors"
+	"math"
 	"net/url"
 	"slices"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -11,27 +13,79 @@ import (
 	"golang.org/x/net/html"
 )
 
+// ImageNodeConstraints configure the image [NodeTester].
+type ImageNodeConstraints struct {
+	AltText StringConstraints
+
+	// MaximumByteSize limits the weight of each image file.
+	MaximumByteSize int
+
+	// MaximumInlineByteSize limits the weight of images
+	// embedded into the page using data: URLs.
+	MaximumInlineByteSize int
+
+	// MaximumDisplayRatio limits how much wider an image file
+	// can be than its declared display width. The default
+	// allows for high density screens.
+	MaximumDisplayRatio float64
+}
+
 func NewImageNodeTester(s StringConstraints) NodeTester {
-	if s.Normalizer == nil {
-		s.Normalizer = NormalizeLineToNFC
+	return NewImageNodeTesterWithConstraints(ImageNodeConstraints{AltText: s})
+}
+
+// NewImageNodeTesterWithConstraints also limits image
+// weight and display size.
+func NewImageNodeTesterWithConstraints(constraints ImageNodeConstraints) NodeTester {
+	if constraints.AltText.Normalizer == nil {
+		constraints.AltText.Normalizer = NormalizeLineToNFC
 	}
-	if s.MinimumLength < 1 {
-		s.MinimumLength = DefaultMinimumImageAltTextLength
+	if constraints.AltText.MinimumLength < 1 {
+		constraints.AltText.MinimumLength = DefaultMinimumImageAltTextLength
 	}
-	if s.MaximumLength < 1 {
-		s.MaximumLength = DefaultMaximumImageAltTextLength
+	if constraints.AltText.MaximumLength < 1 {
+		constraints.AltText.MaximumLength = DefaultMaximumImageAltTextLength
+	}
+	if constraints.MaximumByteSize < 1 {
+		constraints.MaximumByteSize = DefaultMaximumImageByteSize
+	}
+	if constraints.MaximumInlineByteSize < 1 {
+		constraints.MaximumInlineByteSize = DefaultMaximumImageInlineByteSize
+	}
+	if constraints.MaximumDisplayRatio <= 1 {
+		constraints.MaximumDisplayRatio = DefaultMaximumImageDisplayRatio
 	}
 	return image{
-		Normalizer:    s.Normalizer,
-		MinimumLength: s.MinimumLength,
-		MaximumLength: s.MaximumLength,
+		Normalizer:            constraints.AltText.Normalizer,
+		MinimumLength:         constraints.AltText.MinimumLength,
+		MaximumLength:         constraints.AltText.MaximumLength,
+		MaximumByteSize:       constraints.MaximumByteSize,
+		MaximumInlineByteSize: constraints.MaximumInlineByteSize,
+		MaximumDisplayRatio:   constraints.MaximumDisplayRatio,
 	}
 }
 
+// imageAspectRatioTolerance absorbs rounding of
+// declared dimensions to whole pixels.
+const imageAspectRatioTolerance = 0.02
+
 type image struct {
-	Normalizer    Normalizer
-	MinimumLength int
-	MaximumLength int
+	Normalizer            Normalizer
+	MinimumLength         int
+	MaximumLength         int
+	MaximumByteSize       int
+	MaximumInlineByteSize int
+	MaximumDisplayRatio   float64
+}
+
+// imageFile describes a loaded image. Width and height
+// are zero when the dimensions could not be decoded.
+type imageFile struct {
+	URL         string
+	ContentType string
+	Size        int
+	Width       int
+	Height      int
 }
 
 func (i image) Match(t testing.TB, node *html.Node) bool {
@@ -43,16 +97,18 @@ func (i image) ListResourcesForPreloading(origin *url.URL, node *html.Node) (URL
 		if attr.Key != "src" {
 			continue
 		}
-		if strings.HasPrefix(attr.Val, "data:") {
+		if attr.Val != "" && !isDataURL(attr.Val) {
 			URLs = append(URLs, joinRelativePath(origin, attr.Val))
 		}
 		break // only take the first attribute
 	}
-	for _, src := range GetPictureSourceList(node) {
-		if strings.HasPrefix(src, "data:") {
-			continue
+	for _, set := range getSourceSets(node) {
+		candidates, _ := ParseSourceSet(set.SourceSet)
+		for _, candidate := range candidates {
+			if !isDataURL(candidate.URL) {
+				URLs = append(URLs, joinRelativePath(origin, candidate.URL))
+			}
 		}
-		URLs = append(URLs, joinRelativePath(origin, src))
 	}
 	return URLs
 }
@@ -113,63 +169,266 @@ func (i image) TestNode(t testing.TB, origin *url.URL, node *html.Node, loader L
 		t.Log("If you are loading images lazily with JavaScript, stop,")
 		t.Log("and use modern loading=\"lazy\" attribute instead.")
 		t.Error("empty <image[src]> source")
-	} else {
-		validateImage(t, origin, src, loader)
 	}
-	for _, src = range GetPictureSourceList(node) {
-		if src == "" {
-			t.Error("empty <picture[srcset]> source")
-			continue
+
+	width, height := i.validateDeclaredDimensions(t, attributes)
+	sets := getSourceSets(node)
+	if ok && src != "" {
+		if file, ok := i.validateImage(t, origin, src, loader); ok {
+			i.validateIntrinsicDimensions(t, file, width, height, len(sets) > 0)
+			if isLegacyImageFormat(file.ContentType) && !hasModernImageAlternative(node) {
+				t.Logf("%s %s image %q has no WebP or AVIF alternative", internal.WP, file.ContentType, file.URL)
+			}
 		}
-		validateImage(t, origin, src, loader)
+	}
+	for _, set := range sets {
+		i.validateSourceSet(t, origin, set, loader)
 	}
 }
 
-func GetPictureSourceList(node *html.Node) (result []string) {
-	if node.Parent.Type != html.ElementNode || node.Parent.Data != "picture" {
+// validateDeclaredDimensions returns zero for dimensions
+// that are absent or invalid.
+func (i image) validateDeclaredDimensions(t testing.TB, attributes map[string]string) (width, height int) {
+	parse := func(name string) int {
+		value, ok := attributes[name]
+		if !ok {
+			return 0
+		}
+		dimension, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || dimension < 0 {
+			t.Errorf("<img[%s]> %q is not a valid non-negative integer", name, value)
+			return 0
+		}
+		return dimension
+	}
+	width, height = parse("width"), parse("height")
+
+	_, hasWidth := attributes["width"]
+	_, hasHeight := attributes["height"]
+	switch {
+	case !hasWidth && !hasHeight:
+		t.Log(internal.WP, "missing <img[width]> and <img[height]> attributes cause layout shift")
+	case !hasWidth:
+		t.Log(internal.WP, "missing <img[width]> attribute causes layout shift")
+	case !hasHeight:
+		t.Log(internal.WP, "missing <img[height]> attribute causes layout shift")
+	}
+	return width, height
+}
+
+// validateIntrinsicDimensions compares the declared dimensions
+// with the image file. Responsive images, which offer candidates
+// in <img[srcset]> or <picture><source[srcset]>, are not
+// checked for oversized files.
+func (i image) validateIntrinsicDimensions(
+	t testing.TB,
+	file imageFile,
+	width, height int,
+	responsive bool,
+) {
+	if file.Width == 0 || file.Height == 0 || width == 0 || height == 0 {
 		return
 	}
-	for node := range node.ChildNodes() {
-		if node.Type != html.ElementNode || node.Data != "source" {
+	declared := float64(width) / float64(height)
+	intrinsic := float64(file.Width) / float64(file.Height)
+	if math.Abs(declared-intrinsic)/intrinsic > imageAspectRatioTolerance {
+		t.Errorf(
+			"<img> declared aspect ratio %dx%d does not match image %q aspect ratio %dx%d",
+			width, height, file.URL, file.Width, file.Height,
+		)
+	}
+
+	if file.ContentType == "image/svg+xml" {
+		return // vector images scale without loss
+	}
+	if responsive {
+		return // browser picks the candidate closest to display size
+	}
+	if float64(file.Width) > float64(width)*i.MaximumDisplayRatio {
+		t.Logf(
+			"%s image %q is %dpx wide, but is displayed at %dpx; serve a smaller file or add <img[srcset]>",
+			internal.WP, file.URL, file.Width, width,
+		)
+	}
+}
+
+func isLegacyImageFormat(contentType string) bool {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+		return true
+	default:
+		return false
+	}
+}
+
+// hasModernImageAlternative returns true if the <img> is
+// wrapped in a <picture> that offers a WebP or AVIF <source>.
+func hasModernImageAlternative(node *html.Node) bool {
+	if node.Parent == nil || node.Parent.Type != html.ElementNode || node.Parent.Data != "picture" {
+		return false
+	}
+	for sibling := range node.Parent.ChildNodes() {
+		if sibling.Type != html.ElementNode || sibling.Data != "source" {
 			continue
 		}
-		for _, attr := range node.Attr {
-			if attr.Key != "srcset" || attr.Val == "" {
+		for _, attr := range sibling.Attr {
+			if attr.Key != "type" {
 				continue
 			}
-			for _, src := range strings.Split(attr.Val, ",") {
-				src = strings.TrimSpace(src)
-				if src == "" {
-					continue
-				}
-				src, _, _ = strings.Cut(src, ";") // just the first part
-				result = append(result, src)
+			switch strings.ToLower(strings.TrimSpace(attr.Val)) {
+			case "image/webp", "image/avif":
+				return true
 			}
 		}
 	}
+	return false
+}
+
+// sourceSet holds the responsive image attributes
+// of an <img> or a <picture><source> element.
+type sourceSet struct {
+	Element   string
+	SourceSet string
+	Sizes     string
+	HasSizes  bool
+}
+
+func newSourceSet(node *html.Node) (set sourceSet, ok bool) {
+	set.Element = node.Data
+	hasSourceSet := false
+	for _, attr := range node.Attr {
+		switch attr.Key {
+		case "srcset":
+			if !hasSourceSet {
+				set.SourceSet = attr.Val
+				hasSourceSet = true
+			}
+		case "sizes":
+			if !set.HasSizes {
+				set.Sizes = attr.Val
+				set.HasSizes = true
+			}
+		}
+	}
+	return set, hasSourceSet
+}
+
+// getSourceSets collects the source sets of an <img> element
+// and of the <source> elements of its parent <picture>.
+func getSourceSets(node *html.Node) (result []sourceSet) {
+	if set, ok := newSourceSet(node); ok {
+		result = append(result, set)
+	}
+	if node.Parent == nil || node.Parent.Type != html.ElementNode || node.Parent.Data != "picture" {
+		return result
+	}
+	for sibling := range node.Parent.ChildNodes() {
+		if sibling.Type != html.ElementNode || sibling.Data != "source" {
+			continue
+		}
+		if set, ok := newSourceSet(sibling); ok {
+			result = append(result, set)
+		}
+	}
 	return result
 }
 
-func validateImage(
+// GetPictureSourceList returns the image candidate locations
+// of the <source> elements that accompany an <img> element
+// inside of a <picture>.
+func GetPictureSourceList(node *html.Node) (result []string) {
+	for _, set := range getSourceSets(node) {
+		if set.Element != "source" {
+			continue
+		}
+		candidates, _ := ParseSourceSet(set.SourceSet)
+		for _, candidate := range candidates {
+			result = append(result, candidate.URL)
+		}
+	}
+	return result
+}
+
+func (i image) validateSourceSet(
 	t testing.TB,
 	origin *url.URL,
-	URL string,
+	set sourceSet,
 	loader Loader,
 ) {
-	if strings.HasPrefix(URL, "data:") {
-		// TODO: decode the base64 data and fallthrough
-		return // skip embedded image
+	candidates, err := ParseSourceSet(set.SourceSet)
+	if err != nil {
+		t.Errorf("<%s[srcset]> is not valid: %v", set.Element, err)
+	}
+	if len(candidates) == 0 {
+		t.Errorf("<%s[srcset]> has no image candidates", set.Element)
+		return
 	}
 
-	image, contentType, err := loader.Load(
-		t.Context(),
-		joinRelativePath(origin, URL),
+	withWidth, withoutWidth := 0, 0
+	for _, candidate := range candidates {
+		if candidate.Width > 0 {
+			withWidth++
+		} else {
+			withoutWidth++
+		}
+	}
+	if withWidth > 0 && withoutWidth > 0 {
+		t.Errorf("<%s[srcset]> mixes width and density descriptors", set.Element)
+	}
+	if withWidth > 0 && !set.HasSizes {
+		t.Errorf("<%s[srcset]> with width descriptors requires a [sizes] attribute", set.Element)
+	}
+	if set.HasSizes {
+		if _, err = ParseSizes(set.Sizes); err != nil {
+			t.Errorf("<%s[sizes]> is not valid: %v", set.Element, err)
+		}
+		if withWidth == 0 {
+			t.Logf("%s <%s[sizes]> is ignored without width descriptors in [srcset]", internal.WP, set.Element)
+		}
+	}
+
+	for _, candidate := range candidates {
+		file, ok := i.validateImage(t, origin, candidate.URL, loader)
+		if !ok || candidate.Width == 0 || file.Width == 0 {
+			continue
+		}
+		if candidate.Width != file.Width {
+			t.Errorf(
+				"<%s[srcset]> candidate %q declares %dw, but the image is %dpx wide",
+				set.Element, file.URL, candidate.Width, file.Width,
+			)
+		}
+	}
+}
+
+func (i image) validateImage(
+	t testing.TB,
+	origin *url.URL,
+	URL string,
+	loader Loader,
+) (file imageFile, ok bool) {
+	var (
+		image       []byte
+		contentType string
+		err         error
 	)
+	isEmbedded := isDataURL(URL)
+	if isEmbedded {
+		// embedded images are checked even in short mode,
+		// because they are already part of the page
+		image, contentType, err = dataURLLoaderSingleton.Load(t.Context(), URL)
+		URL = shortDataURL(URL)
+	} else {
+		location := joinRelativePath(origin, URL)
+		validatePassiveContent(t, origin, "image", location)
+		image, contentType, err = loader.Load(t.Context(), location)
+	}
 	if err != nil {
 		if errors.Is(err, Skip) {
-			return
+			return file, false
 		}
 		t.Errorf("unable to load image %q: %v", URL, err)
+		return file, false
 	}
 
 	switch contentType {
@@ -187,7 +446,28 @@ func validateImage(
 
 	if len(image) == 0 {
 		t.Error("empty image data:", contentType)
+		return file, false
+	}
+	if len(image) > i.MaximumByteSize {
+		t.Errorf("image %q is %d bytes, expected %d or less", URL, len(image), i.MaximumByteSize)
+	}
+	if isEmbedded && len(image) > i.MaximumInlineByteSize {
+		t.Logf(
+			"%s embedded image %q is %d bytes, which bloats the HTML document; link images larger than %d bytes instead",
+			internal.WP, URL, len(image), i.MaximumInlineByteSize,
+		)
+	}
+
+	file = imageFile{
+		URL:         URL,
+		ContentType: contentType,
+		Size:        len(image),
+	}
+	file.Width, file.Height, err = internal.DecodeImageDimensions(contentType, image)
+	if err != nil {
+		t.Logf("%s unable to decode image %q dimensions: %v", internal.WP, URL, err)
 	}
+	return file, true
 }
 
 func NewFigureNodeTester(s StringConstraints) NodeTester {
@@ -0,0 +1,158 @@
+package pageseo
+
+import (
+	"bytes"
+	"errors"
+	goimage "image"
+	"image/png"
+	"testing"
+
+	"github.com/dkotik/pageseo/internal"
+)
+
+func TestDecodeImageDimensions(t *testing.T) {
+	encoded := &bytes.Buffer{}
+	if err := png.Encode(encoded, goimage.NewGray(goimage.Rect(0, 0, 40, 30))); err != nil {
+		t.Fatal(err)
+	}
+
+	tcs := []struct {
+		ContentType string
+		Data        []byte
+		Width       int
+		Height      int
+	}{
+		{
+			ContentType: "image/png",
+			Data:        encoded.Bytes(),
+			Width:       40,
+			Height:      30,
+		},
+		{
+			ContentType: "image/svg+xml",
+			Data:        []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 120 60"></svg>`),
+			Width:       120,
+			Height:      60,
+		},
+		{
+			ContentType: "image/svg+xml",
+			Data:        []byte(`<svg width="24px" height="12" viewBox="0 0 120 60"></svg>`),
+			Width:       24,
+			Height:      12,
+		},
+		{
+			ContentType: "image/webp",
+			Data: append(
+				[]byte("RIFF\x1a\x00\x00\x00WEBPVP8L\x0d\x00\x00\x00\x2f"),
+				// 40-1 and 30-1 packed into 14-bit fields
+				0x27, 0x40, 0x07, 0x00, 0, 0, 0, 0, 0, 0, 0, 0,
+			),
+			Width:  40,
+			Height: 30,
+		},
+	}
+
+	for _, tc := range tcs {
+		width, height, err := internal.DecodeImageDimensions(tc.ContentType, tc.Data)
+		if err != nil {
+			t.Fatalf("unable to decode %s: %v", tc.ContentType, err)
+		}
+		if width != tc.Width || height != tc.Height {
+			t.Log("  result:", width, "x", height)
+			t.Log("expected:", tc.Width, "x", tc.Height)
+			t.Fatal("decoded dimensions do not match expected value")
+		}
+	}
+}
+
+func TestImageNodeTester(t *testing.T) {
+	encoded := &bytes.Buffer{}
+	if err := png.Encode(encoded, goimage.NewGray(goimage.Rect(0, 0, 400, 300))); err != nil {
+		t.Fatal(err)
+	}
+	loader := mapLoader{
+		"https://example.com/photo.png":  {ContentType: "image/png", Content: encoded.Bytes()},
+		"https://example.com/broken.png": {Error: errors.New("connection reset")},
+	}
+	tester := NewImageNodeTester(StringConstraints{})
+
+	cases := []struct {
+		Name     string
+		Markup   string
+		Errors   []string
+		NotErrs  []string
+		Logs     []string
+		NotLogs  []string
+		NoErrors bool
+	}{
+		{
+			Name:     "matching dimensions",
+			Markup:   `<img src="/photo.png" alt="A photo" width="400" height="300">`,
+			NoErrors: true,
+			Logs:     []string{"has no WebP or AVIF alternative"},
+		},
+		{
+			Name:   "aspect ratio mismatch",
+			Markup: `<img src="/photo.png" alt="A photo" width="100" height="100">`,
+			Errors: []string{"declared aspect ratio 100x100 does not match"},
+		},
+		{
+			Name:     "oversized file",
+			Markup:   `<img src="/photo.png" alt="A photo" width="100" height="75">`,
+			NoErrors: true,
+			Logs:     []string{"is 400px wide, but is displayed at 100px"},
+		},
+		{
+			Name:     "responsive picture",
+			Markup:   `<picture><source media="(min-width: 800px)" srcset="/large.png"><img src="/photo.png" alt="A photo" width="100" height="75"></picture>`,
+			NoErrors: true,
+			NotLogs:  []string{"displayed at"},
+		},
+		{
+			Name:    "load failure",
+			Markup:  `<img src="/broken.png" alt="A photo" width="100" height="75">`,
+			Errors:  []string{"unable to load image"},
+			NotErrs: []string{"empty Content-Type", "empty image data"},
+		},
+		{
+			Name:     "missing dimensions are a hint",
+			Markup:   `<img src="/photo.png" alt="A photo">`,
+			NoErrors: true,
+			Logs:     []string{"missing <img[width]> and <img[height]> attributes cause layout shift"},
+		},
+		{
+			Name:     "modern alternative",
+			Markup:   `<picture><source type="image/webp" srcset="/photo.webp"><img src="/photo.png" alt="A photo" width="400" height="300"></picture>`,
+			NoErrors: true,
+			NotLogs:  []string{"has no WebP or AVIF alternative"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			r := testNodes(t, tester, "https://example.com/", c.Markup, loader)
+			if c.NoErrors && len(r.Errors) > 0 {
+				t.Fatal("unexpected errors:", r.Errors)
+			}
+			for _, e := range c.Errors {
+				if !r.HasError(e) {
+					t.Errorf("missing error %q in %q", e, r.Errors)
+				}
+			}
+			for _, e := range c.NotErrs {
+				if r.HasError(e) {
+					t.Errorf("unexpected error %q in %q", e, r.Errors)
+				}
+			}
+			for _, l := range c.Logs {
+				if !r.HasLog(l) {
+					t.Errorf("missing log %q in %q", l, r.Logs)
+				}
+			}
+			for _, l := range c.NotLogs {
+				if r.HasLog(l) {
+					t.Errorf("unexpected log %q in %q", l, r.Logs)
+				}
+			}
+		})
+	}
+}
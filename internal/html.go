@@ -38,6 +38,16 @@ func GetAttributes(t testing.TB, node *html.Node) map[string]string {
 	return attrs
 }
 
+// GetAttribute returns the first value of the named node attribute.
+func GetAttribute(node *html.Node, key string) (string, bool) {
+	for _, attr := range node.Attr {
+		if attr.Key == key {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
 func GetFirstElementOrSibling(node *html.Node) *html.Node {
 	for {
 		if node == nil {
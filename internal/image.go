@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"strconv"
+	"strings"
+)
+
+// DecodeImageDimensions reads the intrinsic width and height
+// of an image from its header without decoding pixel data.
+//
+// Vector images report the dimensions declared by their
+// root element or, when those are absent, by the view box.
+func DecodeImageDimensions(contentType string, data []byte) (width, height int, err error) {
+	switch contentType {
+	case "image/jpeg":
+		config, err := jpeg.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return 0, 0, err
+		}
+		return config.Width, config.Height, nil
+	case "image/png":
+		config, err := png.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return 0, 0, err
+		}
+		return config.Width, config.Height, nil
+	case "image/gif":
+		config, err := gif.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return 0, 0, err
+		}
+		return config.Width, config.Height, nil
+	case "image/webp":
+		return decodeWebPDimensions(data)
+	case "image/avif":
+		return decodeAVIFDimensions(data)
+	case "image/svg+xml":
+		return decodeSVGDimensions(data)
+	default:
+		return 0, 0, fmt.Errorf("unsupported image type: %s", contentType)
+	}
+}
+
+func decodeWebPDimensions(data []byte) (width, height int, err error) {
+	if len(data) < 30 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return 0, 0, errors.New("invalid WebP header")
+	}
+	chunk := data[20:]
+	switch string(data[12:16]) {
+	case "VP8 ": // lossy: frame tag, start code, then 14-bit dimensions
+		if chunk[3] != 0x9d || chunk[4] != 0x01 || chunk[5] != 0x2a {
+			return 0, 0, errors.New("invalid WebP VP8 start code")
+		}
+		width = int(binary.LittleEndian.Uint16(chunk[6:8]) & 0x3fff)
+		height = int(binary.LittleEndian.Uint16(chunk[8:10]) & 0x3fff)
+	case "VP8L": // lossless: signature, then packed 14-bit dimensions minus one
+		if chunk[0] != 0x2f {
+			return 0, 0, errors.New("invalid WebP VP8L signature")
+		}
+		bits := binary.LittleEndian.Uint32(chunk[1:5])
+		width = int(bits&0x3fff) + 1
+		height = int(bits>>14&0x3fff) + 1
+	case "VP8X": // extended: flags, reserved, then 24-bit canvas dimensions minus one
+		width = int(uint32(chunk[4])|uint32(chunk[5])<<8|uint32(chunk[6])<<16) + 1
+		height = int(uint32(chunk[7])|uint32(chunk[8])<<8|uint32(chunk[9])<<16) + 1
+	default:
+		return 0, 0, fmt.Errorf("unknown WebP chunk: %q", data[12:16])
+	}
+	return width, height, nil
+}
+
+// decodeAVIFDimensions looks for the first image spatial extents
+// property box, which describes the primary item in files produced
+// by common encoders.
+func decodeAVIFDimensions(data []byte) (width, height int, err error) {
+	if len(data) < 12 || string(data[4:8]) != "ftyp" {
+		return 0, 0, errors.New("invalid AVIF header")
+	}
+	i := bytes.Index(data, []byte("ispe"))
+	// box type, version and flags, then two 32-bit dimensions
+	if i < 4 || len(data) < i+16 {
+		return 0, 0, errors.New("AVIF image spatial extents are absent")
+	}
+	width = int(binary.BigEndian.Uint32(data[i+8 : i+12]))
+	height = int(binary.BigEndian.Uint32(data[i+12 : i+16]))
+	return width, height, nil
+}
+
+func decodeSVGDimensions(data []byte) (width, height int, err error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return 0, 0, fmt.Errorf("unable to find <svg> root element: %w", err)
+		}
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if element.Name.Local != "svg" {
+			return 0, 0, errors.New("root element is not <svg>")
+		}
+		var viewBox []string
+		for _, attr := range element.Attr {
+			switch attr.Name.Local {
+			case "width":
+				width = parseSVGLength(attr.Value)
+			case "height":
+				height = parseSVGLength(attr.Value)
+			case "viewBox":
+				viewBox = strings.Fields(strings.ReplaceAll(attr.Value, ",", " "))
+			}
+		}
+		if (width == 0 || height == 0) && len(viewBox) == 4 {
+			width = parseSVGLength(viewBox[2])
+			height = parseSVGLength(viewBox[3])
+		}
+		if width == 0 || height == 0 {
+			return 0, 0, errors.New("<svg> element does not declare its dimensions")
+		}
+		return width, height, nil
+	}
+}
+
+// parseSVGLength returns zero for relative lengths,
+// which do not describe intrinsic dimensions.
+func parseSVGLength(value string) int {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "px")
+	length, err := strconv.ParseFloat(value, 64)
+	if err != nil || length < 0 {
+		return 0
+	}
+	return int(length + 0.5)
+}
@@ -0,0 +1,318 @@
+package pageseo
+
+import (
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dkotik/pageseo/internal"
+	"golang.org/x/net/html"
+)
+
+// NewLandmarkNodeTester checks the accessibility structure
+// of the page <body>: landmark regions, skip links, form
+// control labels, and button names.
+func NewLandmarkNodeTester() NodeTester {
+	return landmark{}
+}
+
+type landmark struct{}
+
+// implicitLandmarkRoles maps elements to the ARIA landmark
+// roles they carry without a [role] attribute. The <header>
+// and <footer> elements are only landmarks outside of
+// sectioning content, see [landmarkRole].
+var implicitLandmarkRoles = map[string]string{
+	"main":   "main",
+	"nav":    "navigation",
+	"aside":  "complementary",
+	"header": "banner",
+	"footer": "contentinfo",
+	"search": "search",
+}
+
+// landmarkWalk collects the accessibility structure of a page.
+type landmarkWalk struct {
+	IDs       map[string]*html.Node
+	Labels    map[string]bool // form control identifiers with a <label[for]>
+	Landmarks map[string][]*html.Node
+	Focusable *html.Node // first element reached by the Tab key
+	Controls  []*html.Node
+	Buttons   []*html.Node
+	Redundant []*html.Node // landmarks with an explicit implicit role
+	Nested    []string     // header and footer nesting violations
+}
+
+func (l landmark) Match(t testing.TB, node *html.Node) bool {
+	return node.Type == html.ElementNode && node.Data == "body"
+}
+
+func (l landmark) ListResourcesForPreloading(origin *url.URL, node *html.Node) []string {
+	return nil
+}
+
+func isElementHidden(node *html.Node) bool {
+	for _, attr := range node.Attr {
+		switch attr.Key {
+		case "hidden":
+			return true
+		case "aria-hidden":
+			if strings.EqualFold(attr.Val, "true") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// landmarkRole returns the explicit or implicit landmark
+// role of an element given the elements that contain it.
+func landmarkRole(node *html.Node, ancestors []string) string {
+	if role, ok := internal.GetAttribute(node, "role"); ok {
+		if fields := strings.Fields(strings.ToLower(role)); len(fields) > 0 {
+			return fields[0] // first token is the one browsers recognize
+		}
+	}
+	role := implicitLandmarkRoles[node.Data]
+	switch node.Data {
+	case "header", "footer":
+		for _, ancestor := range ancestors {
+			switch ancestor {
+			case "article", "aside", "main", "nav", "section":
+				return "" // scoped to the sectioning content
+			}
+		}
+	}
+	return role
+}
+
+func (w *landmarkWalk) Walk(node *html.Node, ancestors []string) {
+	for child := range node.ChildNodes() {
+		if child.Type != html.ElementNode || isElementHidden(child) {
+			continue
+		}
+		if role := landmarkRole(child, ancestors); role != "" {
+			w.Landmarks[role] = append(w.Landmarks[role], child)
+			if _, ok := internal.GetAttribute(child, "role"); ok && implicitLandmarkRoles[child.Data] == role {
+				w.Redundant = append(w.Redundant, child)
+			}
+		}
+		if w.Focusable == nil && isFocusable(child) {
+			w.Focusable = child
+		}
+		switch child.Data {
+		case "header", "footer":
+			if slices.Contains(ancestors, "header") || slices.Contains(ancestors, "footer") {
+				w.Nested = append(w.Nested, "<"+child.Data+"> is nested inside of another <header> or <footer>")
+			}
+		case "label":
+			if target, ok := internal.GetAttribute(child, "for"); ok {
+				w.Labels[target] = true
+			}
+		case "input":
+			inputType, _ := internal.GetAttribute(child, "type")
+			switch strings.ToLower(inputType) {
+			case "hidden":
+			case "submit", "reset", "button", "image":
+				w.Buttons = append(w.Buttons, child)
+			default:
+				w.Controls = append(w.Controls, child)
+			}
+		case "select", "textarea":
+			w.Controls = append(w.Controls, child)
+		case "button":
+			w.Buttons = append(w.Buttons, child)
+		}
+		w.Walk(child, append(ancestors, child.Data))
+	}
+}
+
+// isFocusable returns true for elements
+// that are reached by the Tab key.
+func isFocusable(node *html.Node) bool {
+	if _, disabled := internal.GetAttribute(node, "disabled"); disabled {
+		return false
+	}
+	if tabIndex, ok := internal.GetAttribute(node, "tabindex"); ok {
+		index, err := strconv.Atoi(strings.TrimSpace(tabIndex))
+		if err == nil {
+			return index >= 0
+		}
+	}
+	switch node.Data {
+	case "a", "area":
+		_, ok := internal.GetAttribute(node, "href")
+		return ok
+	case "input":
+		inputType, _ := internal.GetAttribute(node, "type")
+		return !strings.EqualFold(inputType, "hidden")
+	case "button", "select", "textarea", "iframe", "summary":
+		return true
+	}
+	editable, ok := internal.GetAttribute(node, "contenteditable")
+	return ok && !strings.EqualFold(editable, "false")
+}
+
+// accessibleName approximates the accessible name computation
+// for the purpose of detecting unnamed elements.
+func (w *landmarkWalk) accessibleName(node *html.Node, withContent bool) string {
+	if labelledBy, ok := internal.GetAttribute(node, "aria-labelledby"); ok {
+		names := []string{}
+		for _, id := range strings.Fields(labelledBy) {
+			if label, ok := w.IDs[id]; ok {
+				names = append(names, internal.GetAndTrimText(label))
+			}
+		}
+		if name := strings.TrimSpace(strings.Join(names, " ")); name != "" {
+			return name
+		}
+	}
+	if label, ok := internal.GetAttribute(node, "aria-label"); ok && strings.TrimSpace(label) != "" {
+		return strings.TrimSpace(label)
+	}
+	if withContent {
+		if text := internal.GetAndTrimText(node); text != "" {
+			return text
+		}
+		for descendant := range node.Descendants() {
+			if descendant.Type == html.ElementNode && descendant.Data == "img" {
+				if alt, ok := internal.GetAttribute(descendant, "alt"); ok && strings.TrimSpace(alt) != "" {
+					return strings.TrimSpace(alt)
+				}
+			}
+		}
+	}
+	if title, ok := internal.GetAttribute(node, "title"); ok && strings.TrimSpace(title) != "" {
+		return strings.TrimSpace(title)
+	}
+	return ""
+}
+
+func (w *landmarkWalk) hasLabel(control *html.Node) bool {
+	if w.accessibleName(control, false) != "" {
+		return true
+	}
+	if id, ok := internal.GetAttribute(control, "id"); ok && w.Labels[id] {
+		return true
+	}
+	for ancestor := range control.Ancestors() {
+		if ancestor.Type == html.ElementNode && ancestor.Data == "label" {
+			return true
+		}
+	}
+	return false
+}
+
+func (l landmark) TestNode(t testing.TB, origin *url.URL, node *html.Node, loader Loader) {
+	w := &landmarkWalk{
+		IDs:       make(map[string]*html.Node),
+		Labels:    make(map[string]bool),
+		Landmarks: make(map[string][]*html.Node),
+	}
+	for descendant := range node.Descendants() {
+		if descendant.Type != html.ElementNode {
+			continue
+		}
+		// hidden elements can still label other elements
+		if id, ok := internal.GetAttribute(descendant, "id"); ok && id != "" {
+			if _, ok = w.IDs[id]; !ok {
+				w.IDs[id] = descendant
+			}
+		}
+		if name, ok := internal.GetAttribute(descendant, "name"); ok && name != "" && descendant.Data == "a" {
+			if _, ok = w.IDs[name]; !ok {
+				w.IDs[name] = descendant
+			}
+		}
+	}
+	w.Walk(node, nil)
+
+	for _, redundant := range w.Redundant {
+		role, _ := internal.GetAttribute(redundant, "role")
+		t.Logf("%s <%s[role=%q]> is redundant", internal.WP, redundant.Data, role)
+	}
+
+	switch mains := len(w.Landmarks["main"]); mains {
+	case 0:
+		t.Log(internal.WP, "add a <main> element to the page")
+	case 1: // as required
+	default:
+		t.Errorf("page has %d main landmarks, expected exactly one", mains)
+	}
+
+	navigation := w.Landmarks["navigation"]
+	if len(navigation) == 0 {
+		t.Log(internal.WP, "add a <nav> element to the page")
+	} else if len(navigation) > 1 {
+		names := make([]string, 0, len(navigation))
+		for _, nav := range navigation {
+			name := w.accessibleName(nav, false)
+			if name == "" {
+				t.Errorf("<%s> is one of %d navigation landmarks and must have an [aria-label] or [aria-labelledby]", nav.Data, len(navigation))
+				continue
+			}
+			if slices.Contains(names, name) {
+				t.Errorf("navigation landmarks share the same name %q", name)
+			}
+			names = append(names, name)
+		}
+	}
+
+	for _, pair := range [...][2]string{
+		{"banner", "header"},
+		{"contentinfo", "footer"},
+	} {
+		role, element := pair[0], pair[1]
+		switch count := len(w.Landmarks[role]); count {
+		case 0:
+			t.Logf("%s add a <%s> element to the page", internal.WP, element)
+		case 1: // as required
+		default:
+			t.Errorf("page has %d top level <%s> %s landmarks, expected at most one", count, element, role)
+		}
+	}
+	for _, nested := range w.Nested {
+		t.Error(nested)
+	}
+
+	if w.Focusable == nil {
+		t.Log(internal.WP, "add a skip link to the main content")
+	} else if href, _ := internal.GetAttribute(w.Focusable, "href"); w.Focusable.Data != "a" || !strings.HasPrefix(href, "#") || len(href) < 2 || strings.EqualFold(href, "#top") {
+		// "#top" scrolls to the top of the document rather than to the content
+		t.Log(internal.WP, "first focusable element of the page is not a skip link to the main content")
+	} else if _, ok := w.IDs[href[1:]]; !ok {
+		t.Errorf("skip link target %q does not exist", href)
+	}
+
+	for _, control := range w.Controls {
+		if !w.hasLabel(control) {
+			id, _ := internal.GetAttribute(control, "id")
+			name, _ := internal.GetAttribute(control, "name")
+			t.Errorf("<%s id=%q name=%q> form control has no associated <label>", control.Data, id, name)
+		}
+	}
+
+	for _, button := range w.Buttons {
+		if button.Data == "input" {
+			inputType, _ := internal.GetAttribute(button, "type")
+			switch strings.ToLower(inputType) {
+			case "submit", "reset":
+				continue // browsers provide a default label
+			case "image":
+				if alt, _ := internal.GetAttribute(button, "alt"); strings.TrimSpace(alt) == "" && w.accessibleName(button, false) == "" {
+					t.Error("<input[type=image]> has no [alt] text")
+				}
+				continue
+			}
+			if value, _ := internal.GetAttribute(button, "value"); strings.TrimSpace(value) != "" {
+				continue
+			}
+		}
+		if w.accessibleName(button, button.Data == "button") == "" {
+			id, _ := internal.GetAttribute(button, "id")
+			t.Errorf("<%s id=%q> button has no accessible name", button.Data, id)
+		}
+	}
+}
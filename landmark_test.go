@@ -0,0 +1,121 @@
+package pageseo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLandmarkNodeTester(t *testing.T) {
+	const skip = `<a href="#content">Skip to content</a>`
+	cases := []struct {
+		Name   string
+		Body   string
+		Errors []string
+		Logs   []string
+
+		// NotReported must be absent from errors and logs.
+		NotReported []string
+	}{
+		{
+			Name: "missing main",
+			Body: skip + `<div id="content"></div>`,
+			Logs: []string{"add a <main> element to the page"},
+		},
+		{
+			Name:   "duplicate main",
+			Body:   skip + `<main id="content"></main><main></main>`,
+			Errors: []string{"page has 2 main landmarks, expected exactly one"},
+		},
+		{
+			Name:        "hidden main is ignored",
+			Body:        skip + `<main id="content"></main><main hidden></main>`,
+			NotReported: []string{"main landmarks"},
+		},
+		{
+			Name:   "unlabeled navigation",
+			Body:   skip + `<nav></nav><nav aria-label="Footer"></nav><main id="content"></main>`,
+			Errors: []string{"<nav> is one of 2 navigation landmarks and must have an [aria-label]"},
+		},
+		{
+			Name:   "navigation with the same name",
+			Body:   skip + `<nav aria-label="Menu"></nav><nav aria-label="Menu"></nav><main id="content"></main>`,
+			Errors: []string{`navigation landmarks share the same name "Menu"`},
+		},
+		{
+			Name:        "labeled navigation",
+			Body:        skip + `<nav aria-label="Primary"></nav><h2 id="f">Footer links</h2><nav aria-labelledby="f"></nav><main id="content"></main>`,
+			NotReported: []string{"navigation landmarks"},
+		},
+		{
+			Name:   "nested header",
+			Body:   skip + `<footer><header></header></footer><main id="content"></main>`,
+			Errors: []string{"<header> is nested inside of another <header> or <footer>"},
+		},
+		{
+			Name:        "sectioning header is not a banner",
+			Body:        skip + `<header></header><main id="content"><article><header></header></article></main>`,
+			NotReported: []string{"top level <header>"},
+		},
+		{
+			Name:   "duplicate banner",
+			Body:   skip + `<header></header><header></header><main id="content"></main>`,
+			Errors: []string{"page has 2 top level <header> banner landmarks"},
+		},
+		{
+			Name: "redundant role",
+			Body: skip + `<main id="content" role="main"></main>`,
+			Logs: []string{`<main[role="main"]> is redundant`},
+		},
+		{
+			Name:        "skip link",
+			Body:        skip + `<main id="content"><a href="/about">About</a></main>`,
+			NotReported: []string{"skip link"},
+		},
+		{
+			Name:   "skip link to a missing target",
+			Body:   `<a href="#missing">Skip to content</a><main id="content"></main>`,
+			Errors: []string{`skip link target "#missing" does not exist`},
+		},
+		{
+			Name: "first focusable element is not a link",
+			Body: `<button>Menu</button>` + skip + `<main id="content"></main>`,
+			Logs: []string{"first focusable element of the page is not a skip link"},
+		},
+		{
+			Name:        "elements outside of the tab order are ignored",
+			Body:        `<a name="top">Top</a><div tabindex="-1">Panel</div><input type="hidden" name="token">` + skip + `<main id="content"></main>`,
+			NotReported: []string{"skip link"},
+		},
+		{
+			Name: "link to the top of the page is not a skip link",
+			Body: `<a href="#top">Top</a><main id="content"></main>`,
+			Logs: []string{"first focusable element of the page is not a skip link"},
+		},
+		{
+			Name: "first link is not a skip link",
+			Body: `<a href="/">Home</a>` + skip + `<main id="content"></main>`,
+			Logs: []string{"first focusable element of the page is not a skip link"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			r := testNodes(t, NewLandmarkNodeTester(), "https://example.com/",
+				"<!DOCTYPE html><html><body>"+c.Body+"</body></html>", nil)
+			for _, e := range c.Errors {
+				if !r.HasError(e) {
+					t.Errorf("missing error %q in %q", e, r.Errors)
+				}
+			}
+			for _, l := range c.Logs {
+				if !r.HasLog(l) {
+					t.Errorf("missing log %q in %q", l, r.Logs)
+				}
+			}
+			for _, n := range c.NotReported {
+				if r.HasError(n) || r.HasLog(n) {
+					t.Errorf("unexpected report %q in %q", n, strings.Join(append(r.Errors, r.Logs...), "; "))
+				}
+			}
+		})
+	}
+}
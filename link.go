@@ -3,6 +3,7 @@ package pageseo
 import (
 	"errors"
 	"net/url"
+	"slices"
 	"strings"
 	"testing"
 
@@ -19,16 +20,36 @@ func NewLinkNodeTester() NodeTester {
 //		hreflang="en-gb" />
 type link struct{}
 
+// linkRelations are the <link[rel]> values checked by the
+// link [NodeTester]. Style sheets have their own tester.
+var linkRelations = []string{
+	"alternate",
+	"preload",
+	"modulepreload",
+	"icon",
+	"apple-touch-icon",
+	"manifest",
+}
+
+// getLinkRelations returns the lower case <link[rel]> keywords.
+func getLinkRelations(node *html.Node) []string {
+	rel, _ := internal.GetAttribute(node, "rel")
+	return strings.Fields(strings.ToLower(rel))
+}
+
 func (s link) Match(t testing.TB, node *html.Node) bool {
 	if node.Type != html.ElementNode || node.Data != "link" {
-		for _, attr := range node.Attr {
-			return attr.Key == "rel" && attr.Val == "alternate"
-		}
+		return false
 	}
-	return false
+	return slices.ContainsFunc(getLinkRelations(node), func(rel string) bool {
+		return slices.Contains(linkRelations, rel)
+	})
 }
 
 func (s link) ListResourcesForPreloading(origin *url.URL, node *html.Node) (URLs []string) {
+	if !slices.Contains(getLinkRelations(node), "alternate") {
+		return nil // other relations are not loaded
+	}
 	for _, attr := range node.Attr {
 		if attr.Key == "href" {
 			if strings.TrimSpace(attr.Val) != "" {
@@ -41,7 +62,7 @@ func (s link) ListResourcesForPreloading(origin *url.URL, node *html.Node) (URLs
 }
 
 func (s link) TestNode(t testing.TB, origin *url.URL, node *html.Node, loader Loader) {
-	href := ""
+	href, as := "", ""
 	for _, attr := range node.Attr {
 		switch attr.Key {
 		case "href":
@@ -51,7 +72,11 @@ func (s link) TestNode(t testing.TB, origin *url.URL, node *html.Node, loader Lo
 				href = attr.Val
 			}
 		case "hreflang":
-			internal.ValidateLanguage(t, attr.Val)
+			if !strings.EqualFold(strings.TrimSpace(attr.Val), "x-default") {
+				internal.ValidateLanguage(t, attr.Val) // x-default marks the fallback page
+			}
+		case "as":
+			as = strings.ToLower(strings.TrimSpace(attr.Val))
 		}
 	}
 
@@ -59,10 +84,15 @@ func (s link) TestNode(t testing.TB, origin *url.URL, node *html.Node, loader Lo
 		t.Error("missing <link[href]> attribute")
 		return
 	}
-	link, contentType, err := loader.Load(
-		t.Context(),
-		joinRelativePath(origin, href),
-	)
+	location := joinRelativePath(origin, href)
+	rel := getLinkRelations(node)
+	validateLinkMixedContent(t, origin, rel, as, location)
+	if !slices.Contains(rel, "alternate") {
+		return
+	}
+
+	validateSecureAlternative(t, location, loader)
+	link, contentType, err := loader.Load(t.Context(), location)
 	if err != nil {
 		if errors.Is(err, Skip) {
 			return
@@ -82,3 +112,30 @@ func (s link) TestNode(t testing.TB, origin *url.URL, node *html.Node, loader Lo
 		t.Error("empty link file")
 	}
 }
+
+// validateLinkMixedContent classifies the resources that browsers
+// fetch for the page by <link[rel]> and <link[as]>. Preloaded
+// images and media are passive content. Everything else,
+// including fonts, is active content.
+func validateLinkMixedContent(t testing.TB, origin *url.URL, rel []string, as, location string) {
+	for _, relation := range rel {
+		element := "<link[rel=" + relation + "]>"
+		switch relation {
+		case "modulepreload", "manifest":
+			validateActiveContent(t, origin, element, location)
+			return
+		case "icon", "apple-touch-icon":
+			validatePassiveContent(t, origin, element, location)
+			return
+		case "preload":
+			element = "<link[rel=preload][as=" + as + "]>"
+			switch as {
+			case "image", "audio", "video":
+				validatePassiveContent(t, origin, element, location)
+			default:
+				validateActiveContent(t, origin, element, location)
+			}
+			return
+		}
+	}
+}
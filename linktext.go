@@ -0,0 +1,116 @@
+package pageseo
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/dkotik/pageseo/internal"
+	"golang.org/x/net/html"
+	"golang.org/x/text/language"
+	"mvdan.cc/xurls/v2"
+)
+
+// DefaultGenericLinkPhrases lists non-descriptive anchor texts
+// by base language subtag. Search engines and screen reader
+// users cannot tell where such links lead out of context.
+var DefaultGenericLinkPhrases = map[string][]string{
+	"en": {
+		"click here", "click", "here", "link", "this link", "more",
+		"read more", "learn more", "more info", "more information",
+		"continue", "continue reading", "go", "this", "this page",
+		"details", "see more", "find out more", "download",
+	},
+	"de": {
+		"hier klicken", "klicken sie hier", "hier", "link", "mehr",
+		"weiterlesen", "mehr lesen", "mehr erfahren", "mehr infos",
+		"weiter", "details", "diese seite", "download",
+	},
+	"fr": {
+		"cliquez ici", "cliquer ici", "ici", "lien", "plus",
+		"lire la suite", "en savoir plus", "plus d'infos", "suite",
+		"continuer", "détails", "cette page", "télécharger",
+	},
+	"es": {
+		"haga clic aquí", "haz clic aquí", "clic aquí", "aquí", "enlace",
+		"más", "leer más", "saber más", "más información", "continuar",
+		"detalles", "esta página", "descargar",
+	},
+	"it": {
+		"clicca qui", "qui", "link", "di più", "leggi di più",
+		"leggi tutto", "scopri di più", "continua", "dettagli",
+		"questa pagina", "scarica",
+	},
+	"pt": {
+		"clique aqui", "aqui", "link", "mais", "leia mais", "saiba mais",
+		"mais informações", "continuar", "detalhes", "esta página", "baixar",
+	},
+	"nl": {
+		"klik hier", "hier", "link", "meer", "lees meer", "meer lezen",
+		"meer informatie", "verder", "details", "deze pagina", "download",
+	},
+	"ru": {
+		"нажмите здесь", "здесь", "тут", "ссылка", "подробнее",
+		"читать далее", "читать дальше", "далее", "узнать больше", "скачать",
+	},
+	"pl": {
+		"kliknij tutaj", "tutaj", "link", "więcej", "czytaj więcej",
+		"dowiedz się więcej", "szczegóły", "dalej", "pobierz",
+	},
+}
+
+// getPageLanguage returns the base language subtag of the closest
+// [lang] attribute or "en" when none can be parsed.
+func getPageLanguage(node *html.Node) string {
+	for ; node != nil; node = node.Parent {
+		if node.Type != html.ElementNode {
+			continue
+		}
+		if lang, ok := internal.GetAttribute(node, "lang"); ok {
+			tag, err := language.Parse(strings.TrimSpace(lang))
+			if err != nil {
+				return "en"
+			}
+			base, _ := tag.Base()
+			return base.String()
+		}
+	}
+	return "en"
+}
+
+// normalizeLinkPhrase lowercases the text and reduces
+// punctuation, arrows, and symbols to single spaces.
+func normalizeLinkPhrase(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '\''
+	}), " ")
+}
+
+// isGenericLinkPhrase returns true if the anchor text
+// matches one of the phrases configured for the language.
+func isGenericLinkPhrase(phrases map[string][]string, lang, text string) bool {
+	text = normalizeLinkPhrase(text)
+	if text == "" {
+		return false
+	}
+	for _, phrase := range phrases[lang] {
+		if normalizeLinkPhrase(phrase) == text {
+			return true
+		}
+	}
+	return false
+}
+
+var reBareURL = xurls.Strict()
+
+// isBareURL returns true if the anchor text is nothing
+// but a web address.
+func isBareURL(text string) bool {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return false
+	}
+	if location := reBareURL.FindString(text); location == text {
+		return true
+	}
+	return strings.HasPrefix(strings.ToLower(text), "www.") && !strings.ContainsAny(text, " \t\n")
+}
@@ -0,0 +1,99 @@
+package pageseo
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestGenericLinkPhrases(t *testing.T) {
+	tree, err := html.Parse(strings.NewReader(`<!DOCTYPE html>
+<html lang="de-AT"><body>
+	<a href="/a">Hier klicken »</a>
+	<p lang="fr"><a href="/b">Lire la suite…</a></p>
+	<a href="/c">Quarterly report</a>
+</body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []bool{true, true, false}
+	i := 0
+	for node := range tree.Descendants() {
+		if node.Type != html.ElementNode || node.Data != "a" {
+			continue
+		}
+		text := getAnchorName(node)
+		if isGenericLinkPhrase(DefaultGenericLinkPhrases, getPageLanguage(node), text) != expected[i] {
+			t.Fatalf("anchor text %q detection does not match expected: %v", text, expected[i])
+		}
+		i++
+	}
+
+	for text, expected := range map[string]bool{
+		"https://example.com/path": true,
+		"www.example.com":          true,
+		"see https://example.com":  false,
+		"Example Domain":           false,
+	} {
+		if isBareURL(text) != expected {
+			t.Fatalf("bare URL detection for %q does not match expected: %v", text, expected)
+		}
+	}
+}
+
+func TestAnchorTextTargets(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Origin    string
+		Body      string
+		Ambiguous bool
+	}{
+		{
+			Name:   "same location written differently",
+			Origin: "https://www.example.com/",
+			Body: `<a href="/about">About us</a>
+				<a href="about">About us</a>
+				<a href="https://www.example.com/about/">About us</a>
+				<a href="https://WWW.example.com/about#team">About us</a>`,
+		},
+		{
+			Name:      "different locations",
+			Origin:    "https://www.example.com/",
+			Body:      `<a href="/about">About us</a><a href="/company/about">About us</a>`,
+			Ambiguous: true,
+		},
+		{
+			Name:      "different queries",
+			Origin:    "https://www.example.com/",
+			Body:      `<a href="/search?q=one">Results</a><a href="/search?q=two">Results</a>`,
+			Ambiguous: true,
+		},
+		{
+			Name:   "external root with and without a slash",
+			Origin: "https://www.example.com/",
+			Body:   `<a href="https://example.org">Partner</a><a href="https://example.org/">Partner</a>`,
+		},
+		{
+			Name:      "relative path from a nested page",
+			Origin:    "https://www.example.com/blog",
+			Body:      `<a href="/archive">Archive</a><a href="archive">Archive</a>`,
+			Ambiguous: true,
+		},
+		{
+			Name:   "fragments of the same page",
+			Origin: "https://www.example.com/",
+			Body:   `<a href="#top">Top</a><a href="#">Top</a>`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			r := testNodes(t, NewAnchorNodeTester(StringConstraints{}), c.Origin,
+				`<!DOCTYPE html><html lang="en"><body>`+c.Body+`</body></html>`, nil)
+			if r.HasError("leads to different locations") != c.Ambiguous {
+				t.Errorf("ambiguous anchor text detection does not match expected %v: %q", c.Ambiguous, r.Errors)
+			}
+		})
+	}
+}
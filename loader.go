@@ -1,6 +1,7 @@
 package pageseo
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -9,6 +10,7 @@ import (
 	"mime"
 	"net/http"
 	"sync"
+	"time"
 
 	"golang.org/x/sync/singleflight"
 )
@@ -45,6 +47,10 @@ type Resource struct {
 
 type fsLoader struct {
 	fs.FS
+
+	// SizeLimit, when set, stops reading the content
+	// after the byte budget of its content type.
+	SizeLimit *SizeLimitConstraints
 }
 
 func NewFS(fs fs.FS) Loader {
@@ -56,24 +62,76 @@ func NewFS(fs fs.FS) Loader {
 	}
 }
 
-func (fs fsLoader) Load(_ context.Context, url string) ([]byte, string, error) {
+// NewFSWithSizeLimit stops reading files after the byte
+// budget of their content type, which is detected from
+// the first bytes, marking the response as
+// [Response.Truncated].
+func NewFSWithSizeLimit(fs fs.FS, c SizeLimitConstraints) Loader {
+	loader := NewFS(fs).(fsLoader)
+	c = c.withDefaults()
+	loader.SizeLimit = &c
+	return loader
+}
+
+func (fs fsLoader) Load(ctx context.Context, url string) ([]byte, string, error) {
+	response, err := fs.LoadResponse(ctx, url)
+	if response == nil {
+		return nil, "", err
+	}
+	return response.Content, response.ContentType, err
+}
+
+func (fs fsLoader) LoadResponse(_ context.Context, url string) (response *Response, err error) {
+	started := time.Now()
 	r, err := fs.FS.Open(url)
 	if err != nil {
-		return nil, "", fmt.Errorf("unable to open <%s>: %w", url, err)
+		return nil, fmt.Errorf("unable to open <%s>: %w", url, err)
 	}
-	data, err := io.ReadAll(r)
+	defer func() {
+		err = errors.Join(err, r.Close())
+	}()
+
+	// content type detection considers at most 512 bytes
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("unable to load <%s>: %w", url, err)
+	}
+	head = head[:n]
+	ct, _, err := mime.ParseMediaType(http.DetectContentType(head))
 	if err != nil {
-		return nil, "", fmt.Errorf("unable to load <%s>: %w", url, err)
+		return nil, fmt.Errorf("unable to parse media type: %w", err)
+	}
+
+	response = &Response{
+		URL:         url,
+		FinalURL:    url,
+		ContentType: ct,
+	}
+	budget := int64(-1)
+	if fs.SizeLimit != nil {
+		budget = fs.SizeLimit.Budget(ct)
+	}
+	body := io.MultiReader(bytes.NewReader(head), r)
+	if budget < 0 {
+		response.Content, err = io.ReadAll(body)
+	} else {
+		response.Content, err = io.ReadAll(io.LimitReader(body, budget+1))
+		if int64(len(response.Content)) > budget {
+			response.Content = response.Content[:budget]
+			response.Truncated = true
+		}
 	}
-	ct, _, err := mime.ParseMediaType(http.DetectContentType(data))
 	if err != nil {
-		return nil, "", fmt.Errorf("unable to parse media type: %w", err)
+		return nil, fmt.Errorf("unable to load <%s>: %w", url, err)
 	}
-	return data, ct, nil
+	response.Duration = time.Since(started)
+	return response, nil
 }
 
 type semaphoreLoader struct {
 	Loaders chan Loader
+	All     []Loader
 }
 
 func NewSemaphore(loaders ...Loader) Loader {
@@ -97,9 +155,41 @@ func NewSemaphore(loaders ...Loader) Loader {
 
 	return semaphoreLoader{
 		Loaders: stack,
+		All:     loaders,
+	}
+}
+
+func (s semaphoreLoader) LoadResponse(ctx context.Context, url string) (*Response, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case loader := <-s.Loaders:
+		response, err := LoadResponse(ctx, loader, url)
+		s.Loaders <- loader
+		return response, err
 	}
 }
 
+func (s semaphoreLoader) TraceRedirects(URL string) []Redirect {
+	for _, loader := range s.All {
+		if chain := TraceRedirects(loader, URL); len(chain) > 0 {
+			return chain
+		}
+	}
+	return nil
+}
+
+// RecordPageLink passes the link to the first loader that keeps
+// track of page links, because the loaders usually share storage.
+func (s semaphoreLoader) RecordPageLink(ctx context.Context, link PageLink) error {
+	for _, loader := range s.All {
+		if _, ok := loader.(PageLinkRecorder); ok {
+			return RecordPageLink(ctx, loader, link)
+		}
+	}
+	return nil
+}
+
 func (s semaphoreLoader) Load(ctx context.Context, url string) (data []byte, contentType string, err error) {
 	select {
 	case <-ctx.Done():
@@ -114,22 +204,27 @@ func (s semaphoreLoader) Load(ctx context.Context, url string) (data []byte, con
 type hotSwapLoader struct {
 	Cursor    int
 	Preloaded []Resource
+	Responses []*Response
 	Loader    Loader
 }
 
 func NewHotSwap(ctx context.Context, loader Loader, URLs []string) Loader {
 	resources := make([]Resource, len(URLs))
+	responses := make([]*Response, len(URLs))
 	wg := sync.WaitGroup{}
 	for i, url := range URLs {
 		wg.Add(1)
 		go func(ctx context.Context, i int, url string) {
-			data, contentType, err := loader.Load(ctx, url)
+			response, err := LoadResponse(ctx, loader, url)
 			resources[i] = Resource{
-				URL:         url,
-				ContentType: contentType,
-				Content:     data,
-				Error:       err,
+				URL:   url,
+				Error: err,
+			}
+			if response != nil {
+				resources[i].ContentType = response.ContentType
+				resources[i].Content = response.Content
 			}
+			responses[i] = response
 			wg.Done()
 		}(ctx, i, url)
 	}
@@ -138,33 +233,54 @@ func NewHotSwap(ctx context.Context, loader Loader, URLs []string) Loader {
 		Loader:    loader,
 		Cursor:    0,
 		Preloaded: resources,
+		Responses: responses,
 	}
 }
 
-func (h hotSwapLoader) Load(ctx context.Context, URL string) ([]byte, string, error) {
+func (h hotSwapLoader) find(URL string) (int, bool) {
 	var i int
 
 	// search forward from cursor
 	for i = h.Cursor; i < len(h.Preloaded); i++ {
-		r := h.Preloaded[i]
-		if r.URL == URL {
+		if h.Preloaded[i].URL == URL {
 			h.Cursor = i + 1 // next time begin iteration from same point
-			return r.Content, r.ContentType, r.Error
+			return i, true
 		}
 	}
 
 	// search backward from cursor
 	for i = h.Cursor - 1; i >= 0; i-- {
-		r := h.Preloaded[i]
-		if r.URL == URL {
-			return r.Content, r.ContentType, r.Error
+		if h.Preloaded[i].URL == URL {
+			return i, true
 		}
 	}
+	return 0, false
+}
 
+func (h hotSwapLoader) Load(ctx context.Context, URL string) ([]byte, string, error) {
+	if i, ok := h.find(URL); ok {
+		r := h.Preloaded[i]
+		return r.Content, r.ContentType, r.Error
+	}
 	// fallback on loader
 	return h.Loader.Load(ctx, URL)
 }
 
+func (h hotSwapLoader) LoadResponse(ctx context.Context, URL string) (*Response, error) {
+	if i, ok := h.find(URL); ok {
+		return h.Responses[i], h.Preloaded[i].Error
+	}
+	return LoadResponse(ctx, h.Loader, URL)
+}
+
+func (h hotSwapLoader) TraceRedirects(URL string) []Redirect {
+	return TraceRedirects(h.Loader, URL)
+}
+
+func (h hotSwapLoader) RecordPageLink(ctx context.Context, link PageLink) error {
+	return RecordPageLink(ctx, h.Loader, link)
+}
+
 type singleFlightLoader struct {
 	Loader
 	*singleflight.Group
@@ -180,22 +296,42 @@ func NewSingleFlightLoader(loader Loader) Loader {
 	}
 }
 
+func (l *singleFlightLoader) TraceRedirects(URL string) []Redirect {
+	return TraceRedirects(l.Loader, URL)
+}
+
+func (l *singleFlightLoader) RecordPageLink(ctx context.Context, link PageLink) error {
+	return RecordPageLink(ctx, l.Loader, link)
+}
+
 func (l *singleFlightLoader) Load(ctx context.Context, URL string) ([]byte, string, error) {
-	result, err, _ := l.Group.Do(URL, func() (any, error) {
-		data, ct, err := l.Loader.Load(ctx, URL)
-		return Resource{
-			URL:         URL,
-			ContentType: ct,
-			Content:     data,
-		}, err
+	response, err := l.LoadResponse(ctx, URL)
+	if response == nil {
+		return nil, "", err
+	}
+	return response.Content, response.ContentType, err
+}
+
+func (l *singleFlightLoader) LoadResponse(ctx context.Context, URL string) (*Response, error) {
+	key := URL
+	if v, ok := ValidatorsFromContext(ctx, URL); ok {
+		// conditional loads may return no content
+		key += "\x00" + v.ETag + "\x00" + v.LastModified
+	}
+	result, err, _ := l.Group.Do(key, func() (any, error) {
+		return LoadResponse(ctx, l.Loader, URL)
 	})
-	r := result.(Resource)
-	return r.Content, r.ContentType, err
+	return result.(*Response), err
 }
 
 type loaderHTTP struct {
 	*http.Client
-	Headers http.Header
+	Headers   http.Header
+	Redirects *redirectLog
+
+	// SizeLimit, when set, stops reading the content
+	// after the byte budget of its content type.
+	SizeLimit *SizeLimitConstraints
 }
 
 func NewHTTPClient(client *http.Client, headers http.Header) Loader {
@@ -203,43 +339,156 @@ func NewHTTPClient(client *http.Client, headers http.Header) Loader {
 		panic("nil HTTP client")
 	}
 	return loaderHTTP{
-		Client:  client,
-		Headers: headers,
+		Client:    client,
+		Headers:   headers,
+		Redirects: &redirectLog{},
 	}
 }
 
-func (web loaderHTTP) Load(ctx context.Context, url string) (data []byte, contentType string, err error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+// NewHTTPClientWithSizeLimit streams the content and stops
+// reading after the byte budget of its content type, marking
+// the response as [Response.Truncated]. Locations that are
+// expected to have no budget by their file extension are
+// requested with HEAD, or with a ranged GET if the server
+// does not support HEAD.
+func NewHTTPClientWithSizeLimit(client *http.Client, headers http.Header, c SizeLimitConstraints) Loader {
+	loader := NewHTTPClient(client, headers).(loaderHTTP)
+	c = c.withDefaults()
+	loader.SizeLimit = &c
+	return loader
+}
+
+func (web loaderHTTP) TraceRedirects(URL string) []Redirect {
+	return web.Redirects.TraceRedirects(URL)
+}
+
+func (web loaderHTTP) Load(ctx context.Context, url string) ([]byte, string, error) {
+	response, err := web.LoadResponse(ctx, url)
+	if response == nil {
+		return nil, "", err
+	}
+	return response.Content, response.ContentType, err
+}
+
+func (web loaderHTTP) do(ctx context.Context, method, url, byteRange string) (*http.Response, []Redirect, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
 	if err != nil {
-		return nil, "", fmt.Errorf("unable to open <%s>: %w", url, err)
+		return nil, nil, fmt.Errorf("unable to open <%s>: %w", url, err)
 	}
 	for key, values := range web.Headers {
 		for _, value := range values {
 			req.Header.Set(key, value)
 		}
 	}
-	resp, err := web.Client.Do(req)
+	if v, ok := ValidatorsFromContext(ctx, url); ok {
+		if v.ETag != "" {
+			req.Header.Set("If-None-Match", v.ETag)
+		}
+		if v.LastModified != "" {
+			req.Header.Set("If-Modified-Since", v.LastModified)
+		}
+	}
+	if byteRange != "" {
+		req.Header.Set("Range", byteRange)
+	}
+	if req.Header.Get("Accept-Encoding") == "" {
+		// also disables transparent decompression,
+		// which hides the transfer size
+		req.Header.Set("Accept-Encoding", acceptEncoding())
+	}
+	var chain []Redirect
+	resp, err := followRedirects(web.Client, &chain).Do(req)
+	if err == nil || errors.Is(err, ErrRedirectLoop) {
+		// a failed load, like a canceled one, must not
+		// erase the chain of a concurrent load
+		web.Redirects.record(url, chain)
+	}
+	if err != nil {
+		return nil, chain, fmt.Errorf("unable to load <%s>: %w", url, err)
+	}
+	return resp, chain, nil
+}
+
+func (web loaderHTTP) LoadResponse(ctx context.Context, url string) (response *Response, err error) {
+	method := http.MethodGet
+	if web.SizeLimit != nil && web.SizeLimit.Budget(guessContentType(url)) == 0 {
+		method = http.MethodHead // only existence and type are needed
+	}
+	started := time.Now()
+	resp, chain, err := web.do(ctx, method, url, "")
+	if err == nil && method == http.MethodHead {
+		switch resp.StatusCode {
+		case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+			_ = resp.Body.Close()
+			method = http.MethodGet
+			resp, chain, err = web.do(ctx, method, url, "bytes=0-0")
+		}
+	}
+	if errors.Is(err, ErrRedirectLoop) {
+		return &Response{URL: url, FinalURL: url, Redirects: chain}, err
+	}
 	if err != nil {
-		return nil, "", fmt.Errorf("unable to load <%s>: %w", url, err)
+		return nil, err
 	}
 	defer func() {
 		err = errors.Join(err, resp.Body.Close())
 	}()
 
-	data, err = io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, "", fmt.Errorf("unable to load <%s>: %w", url, err)
+	response = &Response{
+		URL:        url,
+		FinalURL:   resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Redirects:  chain,
 	}
 	contentTypeRaw := resp.Header.Get(`Content-Type`)
-	contentType, _, err = mime.ParseMediaType(contentTypeRaw)
+	response.ContentType, _, err = mime.ParseMediaType(contentTypeRaw)
+	parseErr := err
+
+	budget := int64(-1)
+	if web.SizeLimit != nil {
+		budget = web.SizeLimit.Budget(response.ContentType)
+	}
+	received := &countingReader{Reader: resp.Body}
+	var body io.ReadCloser
+	if method != http.MethodHead {
+		body, response.ContentEncoding, err = DecodeContent(resp.Header, received)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode <%s>: %w", url, err)
+		}
+		defer func() {
+			err = errors.Join(err, body.Close())
+		}()
+	}
+	switch {
+	case method == http.MethodHead:
+		response.Truncated = resp.ContentLength != 0
+	case budget < 0:
+		response.Content, err = io.ReadAll(body)
+	default:
+		response.Content, err = io.ReadAll(io.LimitReader(body, budget+1))
+		if int64(len(response.Content)) > budget {
+			response.Content = response.Content[:budget]
+			response.Truncated = true
+		}
+		if resp.StatusCode == http.StatusPartialContent {
+			response.Truncated = response.Truncated || isPartialContent(resp.Header, len(response.Content))
+		}
+	}
 	if err != nil {
-		return nil, "", fmt.Errorf("unable to parse header <Content-Type> <%s>: %w", contentTypeRaw, err)
+		return nil, fmt.Errorf("unable to load <%s>: %w", url, err)
 	}
+	response.TransferSize = received.Count
+	response.Duration = time.Since(started)
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		err = fmt.Errorf("HTTP %d error: %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+		// error pages often omit the content type
+		return response, &StatusError{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+		}
+	}
+	if parseErr != nil {
+		return nil, fmt.Errorf("unable to parse header <Content-Type> <%s>: %w", contentTypeRaw, parseErr)
 	}
-	// if contentType == "" {
-	// 	contentType = "application/octet-stream"
-	// }
-	return data, contentType, err
+	return response, nil
 }
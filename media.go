@@ -0,0 +1,298 @@
+package pageseo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/dkotik/pageseo/internal"
+	"golang.org/x/net/html"
+)
+
+// videoHostingDomains are embedded through <iframe>
+// elements and count as video content on the page.
+var videoHostingDomains = []string{
+	"youtube.com",
+	"youtube-nocookie.com",
+	"youtu.be",
+	"vimeo.com",
+	"dailymotion.com",
+	"wistia.com",
+	"wistia.net",
+}
+
+func isVideoIFrame(node *html.Node) bool {
+	if node.Type != html.ElementNode || node.Data != "iframe" {
+		return false
+	}
+	src, _ := internal.GetAttribute(node, "src")
+	location, err := url.Parse(strings.TrimSpace(src))
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(location.Hostname())
+	for _, domain := range videoHostingDomains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasStructuredDataType returns true if any JSON-LD <script>
+// in the document declares an entity of the given type.
+func hasStructuredDataType(document *html.Node, schemaType string) bool {
+	for node := range document.Descendants() {
+		if node.Type != html.ElementNode || node.Data != "script" {
+			continue
+		}
+		if scriptType, _ := internal.GetAttribute(node, "type"); !strings.EqualFold(strings.TrimSpace(scriptType), "application/ld+json") {
+			continue
+		}
+		var data any
+		if err := json.Unmarshal([]byte(internal.GetAndTrimText(node)), &data); err != nil {
+			continue // invalid JSON-LD is reported elsewhere
+		}
+		if containsSchemaType(data, schemaType) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsSchemaType(data any, schemaType string) bool {
+	switch data := data.(type) {
+	case []any:
+		return slices.ContainsFunc(data, func(item any) bool {
+			return containsSchemaType(item, schemaType)
+		})
+	case map[string]any:
+		switch declared := data["@type"].(type) {
+		case string:
+			if declared == schemaType {
+				return true
+			}
+		case []any:
+			if slices.Contains(declared, any(schemaType)) {
+				return true
+			}
+		}
+		for key, value := range data {
+			if key != "@type" && containsSchemaType(value, schemaType) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// listTrackResources returns the <track[src]> locations of a media element.
+func listTrackResources(origin *url.URL, node *html.Node) (URLs []string) {
+	for child := range node.ChildNodes() {
+		if child.Type != html.ElementNode || child.Data != "track" {
+			continue
+		}
+		if src, ok := internal.GetAttribute(child, "src"); ok && strings.TrimSpace(src) != "" {
+			URLs = append(URLs, joinRelativePath(origin, src))
+		}
+	}
+	return URLs
+}
+
+// validateTracks checks the <track> elements of a media element
+// and returns true if any of them provides captions or subtitles.
+func validateTracks(t testing.TB, origin *url.URL, node *html.Node, loader Loader) (hasCaptions bool) {
+	for child := range node.ChildNodes() {
+		if child.Type != html.ElementNode || child.Data != "track" {
+			continue
+		}
+		attributes := internal.GetAttributes(t, child)
+		kind := strings.ToLower(strings.TrimSpace(attributes["kind"]))
+		switch kind {
+		case "", "subtitles":
+			kind = "subtitles" // missing value default
+			if strings.TrimSpace(attributes["srclang"]) == "" {
+				t.Error("<track[kind=subtitles]> requires a [srclang] attribute")
+			}
+			hasCaptions = true
+		case "captions", "descriptions":
+			hasCaptions = true
+		case "chapters", "metadata":
+		default:
+			t.Errorf("<track[kind]> %q is not valid", kind)
+		}
+		if srclang, ok := attributes["srclang"]; ok {
+			internal.ValidateLanguage(t, srclang)
+		}
+		if strings.TrimSpace(attributes["label"]) == "" && kind != "metadata" {
+			t.Log(internal.WP, "add <track[label]> attribute to name the", kind, "track")
+		}
+
+		src := strings.TrimSpace(attributes["src"])
+		if src == "" {
+			t.Error("missing <track[src]> attribute")
+			continue
+		}
+		location := joinRelativePath(origin, src)
+		validateActiveContent(t, origin, "<track[src]>", location)
+		track, contentType, err := loader.Load(t.Context(), location)
+		if err != nil {
+			if errors.Is(err, Skip) {
+				continue
+			}
+			t.Errorf("unable to load track %q: %v", src, err)
+		}
+		switch contentType {
+		case "":
+			t.Error("empty Content-Type for the track file")
+		case "text/vtt":
+		default:
+			t.Log("strange track Content-Type:", contentType)
+		}
+		if len(track) == 0 {
+			t.Error("empty track file")
+		}
+	}
+	return hasCaptions
+}
+
+// hasTranscript looks for a transcript referenced by the media
+// element or placed right next to it.
+func hasTranscript(node *html.Node) bool {
+	if describedBy, ok := internal.GetAttribute(node, "aria-describedby"); ok && strings.TrimSpace(describedBy) != "" {
+		return true
+	}
+	next := internal.GetFirstElementOrSibling(node.NextSibling)
+	for _, candidate := range []*html.Node{next, node.Parent} {
+		if candidate != nil && strings.Contains(strings.ToLower(internal.GetAndTrimText(candidate)), "transcript") {
+			return true
+		}
+	}
+	return false
+}
+
+// validateMediaSources reports audio and video
+// sources loaded over HTTP from an HTTPS page.
+func validateMediaSources(t testing.TB, origin *url.URL, node *html.Node) {
+	if src, ok := internal.GetAttribute(node, "src"); ok && strings.TrimSpace(src) != "" {
+		validatePassiveContent(t, origin, "<"+node.Data+"[src]>", joinRelativePath(origin, strings.TrimSpace(src)))
+	}
+	for child := range node.ChildNodes() {
+		if child.Type == html.ElementNode && child.Data == "source" {
+			if src, ok := internal.GetAttribute(child, "src"); ok && strings.TrimSpace(src) != "" {
+				validatePassiveContent(t, origin, "<source[src]>", joinRelativePath(origin, strings.TrimSpace(src)))
+			}
+		}
+	}
+}
+
+func hasMediaSource(node *html.Node) bool {
+	if src, ok := internal.GetAttribute(node, "src"); ok && strings.TrimSpace(src) != "" {
+		return true
+	}
+	for child := range node.ChildNodes() {
+		if child.Type == html.ElementNode && child.Data == "source" {
+			if src, ok := internal.GetAttribute(child, "src"); ok && strings.TrimSpace(src) != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func NewVideoNodeTester() NodeTester {
+	return video{
+		Poster: NewImageNodeTesterWithConstraints(ImageNodeConstraints{}).(image),
+	}
+}
+
+type video struct {
+	// Poster validates the preview image file.
+	Poster image
+}
+
+func (v video) Match(t testing.TB, node *html.Node) bool {
+	switch node.Type {
+	case html.ElementNode:
+		return node.Data == "video"
+	case html.DocumentNode:
+		t.Cleanup(func() {
+			hasVideo := false
+			for child := range node.Descendants() {
+				if child.Type == html.ElementNode && child.Data == "video" || isVideoIFrame(child) {
+					hasVideo = true
+					break
+				}
+			}
+			if hasVideo && !hasStructuredDataType(node, "VideoObject") {
+				t.Error("page embeds a video, but does not declare VideoObject structured data")
+			}
+		})
+		return false
+	default:
+		return false
+	}
+}
+
+func (v video) ListResourcesForPreloading(origin *url.URL, node *html.Node) (URLs []string) {
+	if poster, ok := internal.GetAttribute(node, "poster"); ok && strings.TrimSpace(poster) != "" && !isDataURL(poster) {
+		URLs = append(URLs, joinRelativePath(origin, poster))
+	}
+	return append(URLs, listTrackResources(origin, node)...)
+}
+
+func (v video) TestNode(t testing.TB, origin *url.URL, node *html.Node, loader Loader) {
+	attributes := internal.GetAttributes(t, node)
+	if !hasMediaSource(node) {
+		t.Error("missing <video[src]> attribute or <source> element")
+	}
+	validateMediaSources(t, origin, node)
+
+	poster, ok := attributes["poster"]
+	if !ok || strings.TrimSpace(poster) == "" {
+		t.Log(internal.WP, "add <video[poster]> image for search result previews")
+	} else {
+		v.Poster.validateImage(t, origin, strings.TrimSpace(poster), loader)
+	}
+
+	if _, ok = attributes["autoplay"]; ok {
+		if _, ok = attributes["muted"]; !ok {
+			t.Error("<video[autoplay]> without [muted] attribute is blocked by browsers")
+		}
+	}
+
+	if !validateTracks(t, origin, node, loader) && !hasTranscript(node) {
+		t.Error("add a <track kind=\"captions\"> element or a transcript to the video")
+	}
+}
+
+func NewAudioNodeTester() NodeTester {
+	return audio{}
+}
+
+type audio struct{}
+
+func (a audio) Match(t testing.TB, node *html.Node) bool {
+	return node.Type == html.ElementNode && node.Data == "audio"
+}
+
+func (a audio) ListResourcesForPreloading(origin *url.URL, node *html.Node) []string {
+	return listTrackResources(origin, node)
+}
+
+func (a audio) TestNode(t testing.TB, origin *url.URL, node *html.Node, loader Loader) {
+	attributes := internal.GetAttributes(t, node)
+	if !hasMediaSource(node) {
+		t.Error("missing <audio[src]> attribute or <source> element")
+	}
+	validateMediaSources(t, origin, node)
+	if _, ok := attributes["autoplay"]; ok {
+		t.Log(internal.WP, "<audio[autoplay]> is blocked by browsers and disorients screen reader users")
+	}
+	if !validateTracks(t, origin, node, loader) && !hasTranscript(node) {
+		t.Error("add a transcript or a <track kind=\"captions\"> element to the audio")
+	}
+}
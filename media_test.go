@@ -0,0 +1,203 @@
+package pageseo
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestVideoStructuredData(t *testing.T) {
+	tree, err := html.Parse(strings.NewReader(`<!DOCTYPE html>
+<html><head>
+	<script type="application/ld+json">{
+		"@context": "https://schema.org",
+		"@graph": [
+			{"@type": "WebPage", "name": "Lorem Ipsum"},
+			{"@type": ["VideoObject", "CreativeWork"], "name": "Lorem Ipsum"}
+		]
+	}</script>
+</head><body>
+	<iframe src="https://www.youtube-nocookie.com/embed/lorem" title="Lorem Ipsum"></iframe>
+	<iframe src="https://example.com/widget" title="Widget"></iframe>
+</body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasStructuredDataType(tree, "VideoObject") {
+		t.Fatal("VideoObject declared inside of a graph was not found")
+	}
+	if hasStructuredDataType(tree, "AudioObject") {
+		t.Fatal("AudioObject was found, but it is not declared")
+	}
+
+	videos := 0
+	for node := range tree.Descendants() {
+		if isVideoIFrame(node) {
+			videos++
+		}
+	}
+	if videos != 1 {
+		t.Fatal("unexpected number of video iframes:", videos)
+	}
+}
+
+func TestVideoNodeTester(t *testing.T) {
+	const captions = `<track kind="captions" srclang="en" label="English" src="/captions.vtt">`
+	poster := Resource{ContentType: "image/gif", Content: []byte("GIF89a\x10\x00\x09\x00\x00\x00\x00;")}
+	loader := mapLoader{
+		"https://www.example.com/poster.gif": poster,
+		"https://www.example.com/large.gif": {
+			ContentType: poster.ContentType,
+			Content:     append(poster.Content, make([]byte, DefaultMaximumImageByteSize)...),
+		},
+		"https://www.example.com/empty.gif": {ContentType: "image/gif"},
+		"https://www.example.com/captions.vtt": {
+			ContentType: "text/vtt",
+			Content:     []byte("WEBVTT\n"),
+		},
+	}
+	cases := []struct {
+		Name   string
+		Body   string
+		Errors []string
+		Logs   []string
+		Clean  bool
+	}{
+		{
+			Name:  "complete",
+			Body:  `<video src="/video.mp4" poster="/poster.gif">` + captions + `</video>`,
+			Clean: true,
+		},
+		{
+			Name:   "missing source",
+			Body:   `<video poster="/poster.gif">` + captions + `</video>`,
+			Errors: []string{"missing <video[src]> attribute or <source> element"},
+		},
+		{
+			Name:   "mixed content source",
+			Body:   `<video poster="/poster.gif"><source src="http://www.example.com/video.mp4">` + captions + `</video>`,
+			Errors: []string{"passive mixed content <source[src]>"},
+		},
+		{
+			Name: "missing poster",
+			Body: `<video src="/video.mp4">` + captions + `</video>`,
+			Logs: []string{"add <video[poster]> image"},
+		},
+		{
+			Name:   "empty poster",
+			Body:   `<video src="/video.mp4" poster="/empty.gif">` + captions + `</video>`,
+			Errors: []string{"empty image data"},
+		},
+		{
+			Name:   "oversized poster",
+			Body:   `<video src="/video.mp4" poster="/large.gif">` + captions + `</video>`,
+			Errors: []string{`image "/large.gif" is`},
+		},
+		{
+			Name:   "audible autoplay",
+			Body:   `<video src="/video.mp4" poster="/poster.gif" autoplay>` + captions + `</video>`,
+			Errors: []string{"<video[autoplay]> without [muted] attribute is blocked by browsers"},
+		},
+		{
+			Name:   "missing captions",
+			Body:   `<video src="/video.mp4" poster="/poster.gif"></video>`,
+			Errors: []string{"add a <track kind=\"captions\"> element or a transcript to the video"},
+		},
+		{
+			Name:  "transcript instead of captions",
+			Body:  `<video src="/video.mp4" poster="/poster.gif" muted autoplay></video><p>Read the transcript.</p>`,
+			Clean: true,
+		},
+		{
+			Name:   "subtitles without language",
+			Body:   `<video src="/video.mp4" poster="/poster.gif"><track src="/captions.vtt" label="Subtitles"></video>`,
+			Errors: []string{"<track[kind=subtitles]> requires a [srclang] attribute"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			r := testNodes(t, NewVideoNodeTester(), "https://www.example.com/",
+				`<!DOCTYPE html><html><head><script type="application/ld+json">{"@type":"VideoObject"}</script></head><body>`+c.Body+"</body></html>", loader)
+			if c.Clean && (len(r.Errors) > 0 || len(r.Logs) > 0) {
+				t.Errorf("unexpected reports: %q %q", r.Errors, r.Logs)
+			}
+			for _, e := range c.Errors {
+				if !r.HasError(e) {
+					t.Errorf("missing error %q in %q", e, r.Errors)
+				}
+			}
+			for _, l := range c.Logs {
+				if !r.HasLog(l) {
+					t.Errorf("missing log %q in %q", l, r.Logs)
+				}
+			}
+		})
+	}
+
+	r := testNodes(t, NewVideoNodeTester(), "https://www.example.com/",
+		`<!DOCTYPE html><html><body><video src="/video.mp4" poster="/poster.gif">`+captions+`</video></body></html>`, loader)
+	if !r.HasError("page embeds a video, but does not declare VideoObject structured data") {
+		t.Errorf("missing structured data error in %q", r.Errors)
+	}
+}
+
+func TestAudioNodeTester(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Body   string
+		Errors []string
+		Logs   []string
+		Clean  bool
+	}{
+		{
+			Name:  "with transcript",
+			Body:  `<audio src="/episode.mp3" aria-describedby="notes"></audio><div id="notes">Notes</div>`,
+			Clean: true,
+		},
+		{
+			Name:   "missing source",
+			Body:   `<audio aria-describedby="notes"></audio>`,
+			Errors: []string{"missing <audio[src]> attribute or <source> element"},
+		},
+		{
+			Name:   "missing transcript",
+			Body:   `<audio src="/episode.mp3"></audio>`,
+			Errors: []string{"add a transcript or a <track kind=\"captions\"> element to the audio"},
+		},
+		{
+			Name: "autoplay",
+			Body: `<audio src="/episode.mp3" autoplay></audio><a href="/transcript">Transcript</a>`,
+			Logs: []string{"<audio[autoplay]> is blocked by browsers"},
+		},
+		{
+			Name:   "mixed content",
+			Body:   `<audio src="http://www.example.com/episode.mp3"></audio><p>Transcript below.</p>`,
+			Errors: []string{"passive mixed content <audio[src]>"},
+		},
+		{
+			Name:   "invalid track kind",
+			Body:   `<audio src="/episode.mp3"><track kind="lyrics" src="/lyrics.vtt"></audio>`,
+			Errors: []string{`<track[kind]> "lyrics" is not valid`},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			r := testNodes(t, NewAudioNodeTester(), "https://www.example.com/",
+				"<!DOCTYPE html><html><body>"+c.Body+"</body></html>", nil)
+			if c.Clean && (len(r.Errors) > 0 || len(r.Logs) > 0) {
+				t.Errorf("unexpected reports: %q %q", r.Errors, r.Logs)
+			}
+			for _, e := range c.Errors {
+				if !r.HasError(e) {
+					t.Errorf("missing error %q in %q", e, r.Errors)
+				}
+			}
+			for _, l := range c.Logs {
+				if !r.HasLog(l) {
+					t.Errorf("missing log %q in %q", l, r.Logs)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package pageseo
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryCacheConstraints configure [NewMemoryCache].
+type MemoryCacheConstraints struct {
+	// MaximumBytes limits the total size of cached content.
+	// Resources larger than the limit are never cached.
+	MaximumBytes int64
+
+	// MaximumEntries limits the number of cached resources.
+	MaximumEntries int
+
+	// TimeToLive expires successful loads. Zero keeps
+	// them until they are evicted.
+	TimeToLive time.Duration
+
+	// ErrorTimeToLive expires failed loads, including
+	// [Skip]. Zero does not cache failures at all.
+	ErrorTimeToLive time.Duration
+}
+
+// CacheStatistics count the cache activity.
+type CacheStatistics struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Entries   int
+	Bytes     int64
+}
+
+// MemoryCache is a [Middleware] that keeps recently used
+// resources in memory within a byte and entry budget,
+// evicting the least recently used ones first. Every
+// wrapped [Loader] shares the same cache.
+type MemoryCache struct {
+	constraints MemoryCacheConstraints
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // front is most recently used
+	stats   CacheStatistics
+}
+
+type memoryCacheEntry struct {
+	URL      string
+	Response *Response
+	Error    error
+	Size     int64
+	Expires  time.Time
+}
+
+// NewMemoryCache creates a bounded least recently used cache.
+func NewMemoryCache(c MemoryCacheConstraints) *MemoryCache {
+	if c.MaximumBytes <= 0 {
+		c.MaximumBytes = DefaultMemoryCacheBytes
+	}
+	if c.MaximumEntries <= 0 {
+		c.MaximumEntries = DefaultMemoryCacheEntries
+	}
+	if c.TimeToLive < 0 || c.ErrorTimeToLive < 0 {
+		panic("negative cache time to live")
+	}
+	return &MemoryCache{
+		constraints: c,
+		entries:     make(map[string]*list.Element),
+		order:       list.New(),
+	}
+}
+
+func (m *MemoryCache) WrapLoader(l Loader) Loader {
+	if l == nil {
+		panic("nil loader")
+	}
+	return memoryCacheLoader{
+		Loader: l,
+		cache:  m,
+	}
+}
+
+// Statistics returns a snapshot of the cache activity.
+func (m *MemoryCache) Statistics() CacheStatistics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
+
+func (m *MemoryCache) get(URL string, now time.Time) (*memoryCacheEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	element, ok := m.entries[URL]
+	if !ok {
+		m.stats.Misses++
+		return nil, false
+	}
+	entry := element.Value.(*memoryCacheEntry)
+	if !entry.Expires.IsZero() && now.After(entry.Expires) {
+		m.remove(element)
+		m.stats.Misses++
+		return nil, false
+	}
+	m.order.MoveToFront(element)
+	m.stats.Hits++
+	return entry, true
+}
+
+func (m *MemoryCache) put(URL string, response *Response, err error, now time.Time) {
+	ttl := m.constraints.TimeToLive
+	if err != nil {
+		ttl = m.constraints.ErrorTimeToLive
+		if ttl == 0 {
+			return // failures are not cached
+		}
+	}
+	entry := &memoryCacheEntry{
+		URL:      URL,
+		Response: response,
+		Error:    err,
+		Size:     int64(len(URL)),
+	}
+	if response != nil {
+		entry.Size += int64(len(response.Content))
+	}
+	if entry.Size > m.constraints.MaximumBytes {
+		return
+	}
+	if ttl > 0 {
+		entry.Expires = now.Add(ttl)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if element, ok := m.entries[URL]; ok {
+		m.remove(element) // loaded in parallel
+	}
+	m.entries[URL] = m.order.PushFront(entry)
+	m.stats.Entries++
+	m.stats.Bytes += entry.Size
+	for m.stats.Bytes > m.constraints.MaximumBytes || m.stats.Entries > m.constraints.MaximumEntries {
+		m.remove(m.order.Back())
+		m.stats.Evictions++
+	}
+}
+
+func (m *MemoryCache) remove(element *list.Element) {
+	entry := m.order.Remove(element).(*memoryCacheEntry)
+	delete(m.entries, entry.URL)
+	m.stats.Entries--
+	m.stats.Bytes -= entry.Size
+}
+
+type memoryCacheLoader struct {
+	Loader Loader
+	cache  *MemoryCache
+}
+
+func (l memoryCacheLoader) Load(ctx context.Context, URL string) ([]byte, string, error) {
+	response, err := l.LoadResponse(ctx, URL)
+	if response == nil {
+		return nil, "", err
+	}
+	return response.Content, response.ContentType, err
+}
+
+func (l memoryCacheLoader) LoadResponse(ctx context.Context, URL string) (*Response, error) {
+	if _, ok := ValidatorsFromContext(ctx, URL); ok {
+		// conditional loads must reach the server
+		return LoadResponse(ctx, l.Loader, URL)
+	}
+	if entry, ok := l.cache.get(URL, time.Now()); ok {
+		return fromCache(entry.Response), entry.Error
+	}
+	response, err := LoadResponse(ctx, l.Loader, URL)
+	if ctx.Err() == nil {
+		l.cache.put(URL, response, err, time.Now())
+	}
+	return response, err
+}
+
+func (l memoryCacheLoader) TraceRedirects(URL string) []Redirect {
+	return TraceRedirects(l.Loader, URL)
+}
+
+func (l memoryCacheLoader) RecordPageLink(ctx context.Context, link PageLink) error {
+	return RecordPageLink(ctx, l.Loader, link)
+}
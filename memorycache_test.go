@@ -0,0 +1,92 @@
+package pageseo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dkotik/pageseo/internal"
+)
+
+func TestMemoryCache(t *testing.T) {
+	loads := 0
+	source := internal.NewMockLoader(func(URL string) (string, error) {
+		loads++
+		if strings.HasSuffix(URL, "missing") {
+			return "", errors.New("not found")
+		}
+		return strings.Repeat("a", 10), nil
+	}, "text/plain")
+
+	cache := NewMemoryCache(MemoryCacheConstraints{
+		MaximumBytes:    45, // four entries of 11 bytes
+		MaximumEntries:  3,
+		ErrorTimeToLive: time.Hour,
+	})
+	loader := cache.WrapLoader(source)
+	ctx := context.Background()
+	for _, URL := range []string{"a", "b", "c", "a", "d", "a", "b"} {
+		if _, _, err := loader.Load(ctx, URL); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// "b" was least recently used when "d" arrived
+	stats := cache.Statistics()
+	if stats.Hits != 2 || stats.Misses != 5 || stats.Evictions != 2 {
+		t.Fatalf("unexpected statistics: %+v", stats)
+	}
+	if stats.Entries != 3 || stats.Bytes != 33 {
+		t.Fatalf("unexpected cache size: %+v", stats)
+	}
+	if loads != 5 {
+		t.Fatal("unexpected number of loads:", loads)
+	}
+
+	bounded := NewMemoryCache(MemoryCacheConstraints{
+		MaximumBytes:   25, // two entries of 11 bytes
+		MaximumEntries: 10,
+	})
+	for _, URL := range []string{"a", "b", "c", "a"} {
+		if _, _, err := bounded.WrapLoader(source).Load(ctx, URL); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// "a" was evicted by size when "c" arrived, then "b" when "a" returned
+	stats = bounded.Statistics()
+	if stats.Hits != 0 || stats.Misses != 4 || stats.Evictions != 2 {
+		t.Fatalf("unexpected statistics of the byte budget: %+v", stats)
+	}
+	if stats.Entries != 2 || stats.Bytes != 22 {
+		t.Fatalf("byte budget was exceeded: %+v", stats)
+	}
+	if loads != 9 {
+		t.Fatal("unexpected number of loads:", loads)
+	}
+
+	for range 2 {
+		if _, _, err := loader.Load(ctx, "missing"); err == nil {
+			t.Fatal("error was not returned")
+		}
+	}
+	if loads != 10 {
+		t.Fatal("error was not cached:", loads)
+	}
+
+	expiring := NewMemoryCache(MemoryCacheConstraints{TimeToLive: time.Nanosecond}).WrapLoader(source)
+	for range 2 {
+		if _, _, err := expiring.Load(ctx, "e"); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if loads != 12 {
+		t.Fatal("expired entry was served:", loads)
+	}
+
+	// nil OnGrow must not panic
+	if _, _, err := NewCache(nil).WrapLoader(source).Load(ctx, "f"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Prometheus text exposition format version.
+const contentTypePrometheus = "text/plain; version=0.0.4; charset=utf-8"
+
+// ServeHTTP writes the statistics in the
+// Prometheus text exposition format.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", contentTypePrometheus)
+	_ = c.WritePrometheus(w)
+}
+
+// WritePrometheus writes the statistics in the
+// Prometheus text exposition format.
+func (c *Collector) WritePrometheus(w io.Writer) error {
+	stats := c.Statistics()
+	hosts := slices.Sorted(maps.Keys(stats))
+	b := bufio.NewWriter(w)
+
+	counter := func(name, help string, value func(HostStatistics) int64) {
+		fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+		for _, host := range hosts {
+			fmt.Fprintf(b, "%s{host=%s} %d\n", name, quote(host), value(stats[host]))
+		}
+	}
+	counter("pageseo_loader_requests_total", "Loads performed by the loader.",
+		func(s HostStatistics) int64 { return s.Requests })
+	counter("pageseo_loader_cache_hits_total", "Loads served by a cache.",
+		func(s HostStatistics) int64 { return s.CacheHits })
+	counter("pageseo_loader_skipped_total", "Loads that were skipped.",
+		func(s HostStatistics) int64 { return s.Skipped })
+	counter("pageseo_loader_errors_total", "Loads that failed without a response.",
+		func(s HostStatistics) int64 { return s.Errors })
+	counter("pageseo_loader_bytes_total", "Size of the loaded content.",
+		func(s HostStatistics) int64 { return s.Bytes })
+
+	const responses = "pageseo_loader_responses_total"
+	fmt.Fprintf(b, "# HELP %s Responses by status class.\n# TYPE %s counter\n", responses, responses)
+	for _, host := range hosts {
+		classes := stats[host].StatusClasses
+		for _, class := range slices.Sorted(maps.Keys(classes)) {
+			fmt.Fprintf(b, "%s{host=%s,class=%q} %d\n", responses, quote(host), class, classes[class])
+		}
+	}
+
+	const latency = "pageseo_loader_duration_seconds"
+	fmt.Fprintf(b, "# HELP %s Load latency.\n# TYPE %s histogram\n", latency, latency)
+	for _, host := range hosts {
+		h := stats[host].Latency
+		var cumulative int64
+		for i, bound := range h.Bounds {
+			cumulative += h.Counts[i]
+			fmt.Fprintf(b, "%s_bucket{host=%s,le=%q} %d\n", latency, quote(host),
+				strconv.FormatFloat(bound.Seconds(), 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(b, "%s_bucket{host=%s,le=\"+Inf\"} %d\n", latency, quote(host), h.Count)
+		fmt.Fprintf(b, "%s_sum{host=%s} %s\n", latency, quote(host),
+			strconv.FormatFloat(h.Sum.Seconds(), 'g', -1, 64))
+		fmt.Fprintf(b, "%s_count{host=%s} %d\n", latency, quote(host), h.Count)
+	}
+	return b.Flush()
+}
+
+// quote escapes a label value for the exposition format.
+func quote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
+
+// WriteSummary prints a table of the statistics by host.
+func (c *Collector) WriteSummary(w io.Writer) error {
+	stats := c.Statistics()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "host\trequests\tcached\tskipped\terrors\t2xx\t3xx\t4xx\t5xx\tbytes\tmean\tmaximum\t")
+	for _, host := range slices.Sorted(maps.Keys(stats)) {
+		s := stats[host]
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%s\t%s\t\n",
+			host, s.Requests, s.CacheHits, s.Skipped, s.Errors,
+			s.StatusClasses["2xx"], s.StatusClasses["3xx"], s.StatusClasses["4xx"], s.StatusClasses["5xx"],
+			s.Bytes, s.Latency.Mean().Round(time.Millisecond), s.Latency.Maximum.Round(time.Millisecond),
+		)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,249 @@
+/*
+Package metrics counts the loads performed by a
+[pageseo.Loader] per host and exposes them through
+[expvar], the Prometheus text exposition format, and
+a summary table.
+*/
+package metrics
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dkotik/pageseo"
+)
+
+// DefaultLatencyBuckets are the upper bounds of the
+// latency histogram buckets.
+var DefaultLatencyBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Histogram counts observed durations by bucket.
+type Histogram struct {
+	// Bounds are the inclusive upper bounds of the buckets.
+	Bounds []time.Duration
+
+	// Counts has one more bucket than Bounds for the
+	// durations that exceed the largest bound.
+	Counts []int64
+
+	Count   int64
+	Sum     time.Duration
+	Maximum time.Duration
+}
+
+func newHistogram(bounds []time.Duration) Histogram {
+	return Histogram{
+		Bounds: bounds,
+		Counts: make([]int64, len(bounds)+1),
+	}
+}
+
+func (h *Histogram) observe(d time.Duration) {
+	i, _ := slices.BinarySearch(h.Bounds, d)
+	h.Counts[i]++
+	h.Count++
+	h.Sum += d
+	h.Maximum = max(h.Maximum, d)
+}
+
+// Mean returns the average duration.
+func (h Histogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// HostStatistics describe the loads of a single host.
+type HostStatistics struct {
+	Requests  int64
+	CacheHits int64
+
+	// Skipped counts the loads that returned [pageseo.Skip].
+	Skipped int64
+
+	// Errors counts the loads that failed without
+	// an HTTP response, like network failures.
+	Errors int64
+
+	// Bytes is the total size of the loaded content.
+	Bytes int64
+
+	// StatusClasses counts responses by status class,
+	// like "2xx" and "4xx".
+	StatusClasses map[string]int64
+	Latency       Histogram
+}
+
+func (s HostStatistics) clone() HostStatistics {
+	s.StatusClasses = maps.Clone(s.StatusClasses)
+	s.Latency.Counts = slices.Clone(s.Latency.Counts)
+	return s
+}
+
+// Collector is a [pageseo.Middleware] that records
+// the loads of every wrapped [pageseo.Loader]. Place it
+// outside of the cache middleware to count cache hits.
+// When the collector wraps a loader more than once, like
+// a crawler and the HTTP client beneath its repository,
+// the outermost wrapper records each load.
+type Collector struct {
+	mu      sync.Mutex
+	buckets []time.Duration
+	hosts   map[string]*HostStatistics
+}
+
+// New creates a collector with latency histogram
+// buckets. Defaults to [DefaultLatencyBuckets].
+func New(buckets ...time.Duration) *Collector {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+	return &Collector{
+		buckets: slices.Compact(buckets),
+		hosts:   make(map[string]*HostStatistics),
+	}
+}
+
+// Statistics returns a snapshot of the loads by host.
+func (c *Collector) Statistics() map[string]HostStatistics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	snapshot := make(map[string]HostStatistics, len(c.hosts))
+	for host, stats := range c.hosts {
+		snapshot[host] = stats.clone()
+	}
+	return snapshot
+}
+
+// Publish exposes the statistics as an [expvar] variable,
+// which is served on "/debug/vars" by [expvar.Handler].
+// Like [expvar.Publish], it panics if the name is taken.
+func (c *Collector) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return c.Statistics()
+	}))
+}
+
+func (c *Collector) observe(URL string, response *pageseo.Response, err error, duration time.Duration) {
+	host := "local"
+	if parsed, parseErr := url.Parse(URL); parseErr == nil && parsed.Host != "" {
+		host = parsed.Host
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats, ok := c.hosts[host]
+	if !ok {
+		stats = &HostStatistics{
+			StatusClasses: make(map[string]int64),
+			Latency:       newHistogram(c.buckets),
+		}
+		c.hosts[host] = stats
+	}
+	stats.Requests++
+	stats.Latency.observe(duration)
+	if response != nil {
+		stats.Bytes += int64(len(response.Content))
+		if response.Cached {
+			stats.CacheHits++
+		}
+		if response.StatusCode > 0 {
+			stats.StatusClasses[statusClass(response.StatusCode)]++
+		}
+	}
+	var statusError *pageseo.StatusError
+	switch {
+	case err == nil:
+	case errors.Is(err, pageseo.Skip):
+		stats.Skipped++
+	case errors.As(err, &statusError):
+		if response == nil {
+			stats.StatusClasses[statusClass(statusError.StatusCode)]++
+		}
+	default:
+		stats.Errors++
+	}
+}
+
+// statusClass groups status codes like "4xx".
+func statusClass(code int) string {
+	return strconv.Itoa(code/100) + "xx"
+}
+
+// WrapLoader records every load of the loader.
+func (c *Collector) WrapLoader(l pageseo.Loader) pageseo.Loader {
+	if l == nil {
+		panic("nil loader")
+	}
+	return loader{Loader: l, Collector: c}
+}
+
+type loader struct {
+	Loader    pageseo.Loader
+	Collector *Collector
+}
+
+type observedContextKey struct{}
+
+// observed marks the load that an outer
+// wrapper of the collector is recording.
+type observed struct {
+	Collector *Collector
+	URL       string
+}
+
+func (l loader) Load(ctx context.Context, URL string) ([]byte, string, error) {
+	response, err := l.LoadResponse(ctx, URL)
+	if response == nil {
+		return nil, "", err
+	}
+	return response.Content, response.ContentType, err
+}
+
+func (l loader) LoadResponse(ctx context.Context, URL string) (*pageseo.Response, error) {
+	mark := observed{Collector: l.Collector, URL: URL}
+	if ctx.Value(observedContextKey{}) == mark {
+		return pageseo.LoadResponse(ctx, l.Loader, URL)
+	}
+	ctx = context.WithValue(ctx, observedContextKey{}, mark)
+	started := time.Now()
+	response, err := pageseo.LoadResponse(ctx, l.Loader, URL)
+	l.Collector.observe(URL, response, err, time.Since(started))
+	return response, err
+}
+
+func (l loader) TraceRedirects(URL string) []pageseo.Redirect {
+	return pageseo.TraceRedirects(l.Loader, URL)
+}
+
+func (l loader) RecordPageLink(ctx context.Context, link pageseo.PageLink) error {
+	return pageseo.RecordPageLink(ctx, l.Loader, link)
+}
+
+func (l loader) SetCrawlDelay(host string, delay time.Duration) {
+	if delayer, ok := l.Loader.(pageseo.CrawlDelayer); ok {
+		delayer.SetCrawlDelay(host, delay)
+	}
+}
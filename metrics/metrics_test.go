@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dkotik/pageseo"
+)
+
+func TestCollector(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("hello"))
+	}))
+	t.Cleanup(server.Close)
+
+	collector := New(time.Millisecond, time.Second)
+	loader := collector.WrapLoader(
+		pageseo.NewMemoryCache(pageseo.MemoryCacheConstraints{}).WrapLoader(
+			pageseo.NewHTTPClient(server.Client(), nil)))
+	for _, path := range []string{"/a", "/a", "/missing"} {
+		_, _, _ = loader.Load(t.Context(), server.URL+path)
+	}
+	offline := collector.WrapLoader(failing{})
+	_, _, _ = offline.Load(t.Context(), "https://example.com/")
+	_, _, _ = offline.Load(t.Context(), "https://example.com/skip")
+
+	stats := collector.Statistics()
+	host := strings.TrimPrefix(server.URL, "http://")
+	s := stats[host]
+	if s.Requests != 3 || s.CacheHits != 1 || s.Bytes != 10+int64(len("404 page not found\n")) {
+		t.Fatalf("unexpected statistics: %+v", s)
+	}
+	if s.StatusClasses["2xx"] != 2 || s.StatusClasses["4xx"] != 1 || s.Latency.Count != 3 {
+		t.Fatalf("unexpected status classes: %+v", s)
+	}
+	if e := stats["example.com"]; e.Errors != 1 || e.Skipped != 1 {
+		t.Fatalf("unexpected failures: %+v", e)
+	}
+
+	recorder := httptest.NewRecorder()
+	collector.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	exposition := recorder.Body.String()
+	for _, line := range []string{
+		`pageseo_loader_requests_total{host="` + host + `"} 3`,
+		`pageseo_loader_responses_total{host="` + host + `",class="4xx"} 1`,
+		`pageseo_loader_duration_seconds_bucket{host="example.com",le="+Inf"} 2`,
+		`pageseo_loader_duration_seconds_count{host="` + host + `"} 3`,
+	} {
+		if !strings.Contains(exposition, line+"\n") {
+			t.Errorf("exposition is missing %q:\n%s", line, exposition)
+		}
+	}
+
+	var summary strings.Builder
+	if err := collector.WriteSummary(&summary); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(summary.String(), host) {
+		t.Fatal("summary is missing the host:", summary.String())
+	}
+}
+
+func TestCollectorWrapsTwice(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("hello"))
+	}))
+	t.Cleanup(server.Close)
+
+	// like a crawler that stores the loads of its HTTP client
+	collector := New()
+	network := collector.WrapLoader(pageseo.NewHTTPClient(server.Client(), nil))
+	cache := pageseo.NewMemoryCache(pageseo.MemoryCacheConstraints{}).WrapLoader(network)
+	for _, loader := range []pageseo.Loader{network, collector.WrapLoader(cache), collector.WrapLoader(cache)} {
+		if _, _, err := loader.Load(t.Context(), server.URL+"/a"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := collector.Statistics()[strings.TrimPrefix(server.URL, "http://")]
+	if s.Requests != 3 || s.CacheHits != 1 {
+		t.Fatalf("loads were not recorded once: %+v", s)
+	}
+}
+
+type failing struct{}
+
+func (failing) Load(_ context.Context, URL string) ([]byte, string, error) {
+	if strings.HasSuffix(URL, "/skip") {
+		return nil, "", pageseo.Skip
+	}
+	return nil, "", errors.New("connection refused")
+}
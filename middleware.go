@@ -16,33 +16,6 @@ func (mf MiddlewareFunc) WrapLoader(l Loader) Loader {
 	return mf(l)
 }
 
-type retryLoader struct {
-	AttemptLimit uint8
-	Loader       Loader
-}
-
-func NewRetry(limit uint8) Middleware {
-	if limit < 2 {
-		panic("retry middleware requires at least two attempts")
-	}
-	return MiddlewareFunc(func(l Loader) Loader {
-		return retryLoader{
-			AttemptLimit: limit,
-			Loader:       l,
-		}
-	})
-}
-
-func (r retryLoader) Load(ctx context.Context, url string) (data []byte, ct string, err error) {
-	for range r.AttemptLimit {
-		data, ct, err = r.Loader.Load(ctx, url)
-		if err == nil {
-			return data, ct, nil
-		}
-	}
-	return data, ct, err
-}
-
 type delayLoader struct {
 	Base   time.Duration
 	Random time.Duration
@@ -59,11 +32,34 @@ func NewDelay(base, random time.Duration) Middleware {
 	})
 }
 
+func (d delayLoader) TraceRedirects(URL string) []Redirect {
+	return TraceRedirects(d.Loader, URL)
+}
+
+func (d delayLoader) RecordPageLink(ctx context.Context, link PageLink) error {
+	return RecordPageLink(ctx, d.Loader, link)
+}
+
+// duration picks a normally distributed delay around the base,
+// which is never negative.
+func (d delayLoader) duration() time.Duration {
+	return max(0, d.Base+time.Duration(rand.NormFloat64()*float64(d.Random)))
+}
+
 func (d delayLoader) Load(ctx context.Context, url string) (data []byte, ct string, err error) {
 	select {
 	case <-ctx.Done():
 		return nil, "", ctx.Err()
-	case <-time.After(d.Base + time.Duration(rand.NormFloat64()*float64(d.Random))):
+	case <-time.After(d.duration()):
 		return d.Loader.Load(ctx, url)
 	}
 }
+
+func (d delayLoader) LoadResponse(ctx context.Context, url string) (*Response, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(d.duration()):
+		return LoadResponse(ctx, d.Loader, url)
+	}
+}
@@ -0,0 +1,97 @@
+package pageseo
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/dkotik/pageseo/internal"
+	"golang.org/x/net/html"
+)
+
+// isMixedContent returns true if an HTTPS page
+// refers to a resource over plain HTTP.
+func isMixedContent(origin *url.URL, location string) bool {
+	if origin == nil || !strings.EqualFold(origin.Scheme, "https") {
+		return false
+	}
+	parsed, err := url.Parse(strings.TrimSpace(location))
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(parsed.Scheme, "http")
+}
+
+// validateActiveContent reports scripts, style sheets,
+// frames, plugins, and form submissions loaded over HTTP
+// from an HTTPS page. Browsers block them outright.
+func validateActiveContent(t testing.TB, origin *url.URL, element, location string) {
+	if isMixedContent(origin, location) {
+		t.Errorf("active mixed content %s %q is blocked by browsers on HTTPS pages", element, location)
+	}
+}
+
+// validatePassiveContent reports images, audio, and video
+// loaded over HTTP from an HTTPS page. Browsers upgrade or
+// block them and mark the page as not fully secure.
+func validatePassiveContent(t testing.TB, origin *url.URL, element, location string) {
+	if isMixedContent(origin, location) {
+		t.Errorf("passive mixed content %s %q marks the HTTPS page as not secure", element, location)
+	}
+}
+
+// validateSecureAlternative reports links to HTTP pages
+// that can also be loaded over HTTPS.
+func validateSecureAlternative(t testing.TB, location string, loader Loader) {
+	secure, ok := getSecureAlternative(location)
+	if !ok {
+		return
+	}
+	_, _, err := loader.Load(t.Context(), secure)
+	if err != nil {
+		return // HTTPS is not available or loading is skipped
+	}
+	t.Errorf("link %q uses HTTP, but the page is available over HTTPS: %q", location, secure)
+}
+
+// getSecureAlternative replaces the HTTP scheme with HTTPS,
+// except for local hosts, which rarely serve HTTPS.
+func getSecureAlternative(location string) (string, bool) {
+	parsed, err := url.Parse(strings.TrimSpace(location))
+	if err != nil || !strings.EqualFold(parsed.Scheme, "http") || IsLocalHost(parsed.Hostname()) {
+		return "", false
+	}
+	parsed.Scheme = "https"
+	parsed.Fragment = ""
+	return parsed.String(), true
+}
+
+func NewFormNodeTester() NodeTester {
+	return form{}
+}
+
+type form struct{}
+
+func (f form) Match(t testing.TB, node *html.Node) bool {
+	return node.Type == html.ElementNode && node.Data == "form"
+}
+
+func (f form) ListResourcesForPreloading(origin *url.URL, node *html.Node) []string {
+	return nil // forms are submitted, not loaded
+}
+
+func (f form) TestNode(t testing.TB, origin *url.URL, node *html.Node, loader Loader) {
+	attributes := internal.GetAttributes(t, node)
+	if action, ok := attributes["action"]; ok && strings.TrimSpace(action) != "" {
+		action = joinRelativePath(origin, strings.TrimSpace(action))
+		validateActiveContent(t, origin, "<form[action]>", action)
+	}
+	for descendant := range node.Descendants() {
+		if descendant.Type != html.ElementNode || descendant.Data != "button" && descendant.Data != "input" {
+			continue
+		}
+		if action, ok := internal.GetAttribute(descendant, "formaction"); ok && strings.TrimSpace(action) != "" {
+			validateActiveContent(t, origin, "<"+descendant.Data+"[formaction]>", joinRelativePath(origin, strings.TrimSpace(action)))
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package pageseo
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestMixedContent(t *testing.T) {
+	secure := &url.URL{Scheme: "https", Host: "example.com", Path: "/"}
+	insecure := &url.URL{Scheme: "http", Host: "example.com", Path: "/"}
+
+	for _, tc := range []struct {
+		Origin   *url.URL
+		Location string
+		Expected bool
+	}{
+		{Origin: secure, Location: "http://cdn.example.com/app.js", Expected: true},
+		{Origin: secure, Location: "HTTP://example.com/style.css", Expected: true},
+		{Origin: secure, Location: "https://cdn.example.com/app.js", Expected: false},
+		{Origin: secure, Location: "//cdn.example.com/app.js", Expected: false},
+		{Origin: secure, Location: "/image.png", Expected: false},
+		{Origin: insecure, Location: "http://cdn.example.com/app.js", Expected: false},
+		{Origin: nil, Location: "http://cdn.example.com/app.js", Expected: false},
+	} {
+		if isMixedContent(tc.Origin, tc.Location) != tc.Expected {
+			t.Errorf("mixed content detection for %q on %v does not match expected: %v", tc.Location, tc.Origin, tc.Expected)
+		}
+	}
+
+	for location, expected := range map[string]string{
+		"http://example.com/page#section": "https://example.com/page",
+		"https://example.com/page":        "",
+		"http://localhost:8080/page":      "",
+		"mailto:lorem@example.com":        "",
+	} {
+		if secure, _ := getSecureAlternative(location); secure != expected {
+			t.Errorf("secure alternative for %q is %q, expected %q", location, secure, expected)
+		}
+	}
+}
+
+func TestLinkMixedContent(t *testing.T) {
+	cases := []struct {
+		Link     string
+		Expected string
+	}{
+		{Link: `<link rel="stylesheet" href="http://cdn.example.com/style.css">`, Expected: "active mixed content style sheet"},
+		{Link: `<link href="http://cdn.example.com/style.css" rel="Stylesheet">`, Expected: "active mixed content style sheet"},
+		{Link: `<link rel="modulepreload" href="http://cdn.example.com/app.js">`, Expected: "active mixed content <link[rel=modulepreload]>"},
+		{Link: `<link rel="preload" as="font" href="http://cdn.example.com/font.woff2">`, Expected: "active mixed content <link[rel=preload][as=font]>"},
+		{Link: `<link rel="preload" as="image" href="http://cdn.example.com/hero.webp">`, Expected: "passive mixed content <link[rel=preload][as=image]>"},
+		{Link: `<link rel="shortcut icon" href="http://cdn.example.com/favicon.ico">`, Expected: "passive mixed content <link[rel=icon]>"},
+		{Link: `<link rel="preload" as="image" href="https://cdn.example.com/hero.webp">`},
+		{Link: `<link rel="alternate" hreflang="x-default" href="https://example.com/">`},
+	}
+	for _, c := range cases {
+		document := `<!DOCTYPE html><html><head>` + c.Link + `</head><body></body></html>`
+		r := testNodes(t, NewLinkNodeTester(), "https://example.com/", document, nil)
+		stylesheet := testNodes(t, NewStyleSheetNodeTester(), "https://example.com/", document, nil)
+		errors := append(r.Errors, stylesheet.Errors...)
+		switch {
+		case c.Expected == "" && len(errors) > 0:
+			t.Errorf("unexpected errors for %s: %q", c.Link, errors)
+		case c.Expected != "" && !r.HasError(c.Expected) && !stylesheet.HasError(c.Expected):
+			t.Errorf("missing error %q for %s in %q", c.Expected, c.Link, errors)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package pageseo
+
+import "context"
+
+// PageLink is a hyperlink discovered on a page.
+type PageLink struct {
+	Source string
+	Target string
+	Text   string
+	Rel    []string
+}
+
+// PageLinkRecorder keeps track of the links between pages.
+// [Loader]s may implement it to receive every hyperlink
+// discovered by the anchor [NodeTester].
+type PageLinkRecorder interface {
+	RecordPageLink(context.Context, PageLink) error
+}
+
+// RecordPageLink passes the link to the loader, if the loader
+// keeps track of page links. Loaders that wrap other loaders
+// call it to forward the links to the wrapped loader.
+func RecordPageLink(ctx context.Context, loader Loader, link PageLink) error {
+	recorder, ok := loader.(PageLinkRecorder)
+	if !ok {
+		return nil
+	}
+	return recorder.RecordPageLink(ctx, link)
+}
@@ -0,0 +1,50 @@
+package pageseo
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+type linkRecorder struct {
+	Loader
+	Links []PageLink
+}
+
+func (r *linkRecorder) RecordPageLink(_ context.Context, link PageLink) error {
+	r.Links = append(r.Links, link)
+	return nil
+}
+
+func TestRecordPageLinkThroughWrappers(t *testing.T) {
+	wrappers := map[string]func(Loader) Loader{
+		"memory cache":  NewMemoryCache(MemoryCacheConstraints{}).WrapLoader,
+		"cache":         NewCache(nil).WrapLoader,
+		"retry":         NewRetry(2).WrapLoader,
+		"size limit":    NewSizeLimit(SizeLimitConstraints{}).WrapLoader,
+		"delay":         NewDelay(time.Millisecond, 0).WrapLoader,
+		"rate limit":    NewRateLimit(RateLimitConstraints{}).WrapLoader,
+		"single flight": NewSingleFlightLoader,
+		"semaphore":     func(l Loader) Loader { return NewSemaphore(l, l) },
+		"data URL":      NewDataURLLoader,
+		"hot swap": func(l Loader) Loader {
+			return NewHotSwap(t.Context(), l, nil)
+		},
+		"response adapter": func(l Loader) Loader {
+			return NewLoaderFromResponses(NewResponseLoader(l))
+		},
+	}
+	for name, wrap := range wrappers {
+		t.Run(name, func(t *testing.T) {
+			recorder := &linkRecorder{Loader: NewFS(fstest.MapFS{})}
+			link := PageLink{Source: "https://example.com/", Target: "https://example.com/about"}
+			if err := RecordPageLink(t.Context(), wrap(recorder), link); err != nil {
+				t.Fatal(err)
+			}
+			if len(recorder.Links) != 1 || recorder.Links[0].Target != link.Target {
+				t.Fatalf("the link was not forwarded to the wrapped loader: %+v", recorder.Links)
+			}
+		})
+	}
+}
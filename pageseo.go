@@ -67,6 +67,7 @@ type NodeTester interface {
 
 type PageTester interface {
 	TestPage(string, []byte) func(t *testing.T)
+	TestResponse(*Response) func(t *testing.T)
 	TestFile(string) func(t *testing.T)
 }
 
@@ -113,6 +114,18 @@ func (p pageSEO) TestPage(URL string, content []byte) func(t *testing.T) {
 	}
 }
 
+// TestResponse tests the page of an already loaded response
+// and how it was compressed for the transfer.
+func (p pageSEO) TestResponse(response *Response) func(t *testing.T) {
+	return func(t *testing.T) {
+		if response == nil {
+			t.Fatal("no response")
+		}
+		validateCompression(t, response.URL, response)
+		p.TestPage(response.URL, response.Content)(t)
+	}
+}
+
 func (p pageSEO) TestFile(path string) func(t *testing.T) {
 	return func(t *testing.T) {
 		f, err := os.Open(path)
@@ -208,18 +221,6 @@ func (p pageSEO) TestTree(origin *url.URL, node *html.Node) func(t *testing.T) {
 			}
 		}
 
-		foundNav, foundHeader, foundFooter := false, false, false
-		t.Cleanup(func() {
-			if foundNav == false {
-				t.Log("add a <nav> element to the page")
-			}
-			if foundHeader == false {
-				t.Log("add a <header> element to the page")
-			}
-			if foundFooter == false {
-				t.Log("add a <footer> element to the page")
-			}
-		})
 		testsToRun := make([]nodeTests, 0, 8)
 		reploadURLs := make([]string, 0, 8)
 		packTests := func(node *html.Node, nts []NodeTester) {
@@ -230,15 +231,6 @@ func (p pageSEO) TestTree(origin *url.URL, node *html.Node) func(t *testing.T) {
 					reploadURLs = append(reploadURLs, nt.ListResourcesForPreloading(
 						origin, node,
 					)...)
-				} else if node.Type == html.ElementNode {
-					switch node.Data {
-					case "nav":
-						foundNav = true
-					case "header":
-						foundHeader = true
-					case "footer":
-						foundFooter = true
-					}
 				}
 			}
 			if len(next) == 0 {
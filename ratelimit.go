@@ -0,0 +1,236 @@
+package pageseo
+
+import (
+	"context"
+	"math"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HostRateLimit throttles requests to a single host
+// with a token bucket.
+type HostRateLimit struct {
+	RequestsPerSecond float64
+	Burst             int
+
+	// Concurrency caps the number of requests
+	// to the host that are in flight at once.
+	Concurrency int
+
+	// CrawlDelay is the least amount of time between
+	// requests. It overrides the burst, because each
+	// request must wait for the full delay.
+	CrawlDelay time.Duration
+}
+
+func (l HostRateLimit) withDefaults(fallback HostRateLimit) HostRateLimit {
+	if l.RequestsPerSecond <= 0 {
+		l.RequestsPerSecond = fallback.RequestsPerSecond
+	}
+	if l.Burst < 1 {
+		l.Burst = fallback.Burst
+	}
+	if l.Concurrency < 1 {
+		l.Concurrency = fallback.Concurrency
+	}
+	if l.CrawlDelay <= 0 {
+		l.CrawlDelay = fallback.CrawlDelay
+	}
+	return l
+}
+
+// RateLimitConstraints configure [NewRateLimit].
+type RateLimitConstraints struct {
+	// Default applies to every host that is not listed in Hosts.
+	Default HostRateLimit
+
+	// Hosts override the limits by host name, which may
+	// include a port, like "localhost:8080".
+	Hosts map[string]HostRateLimit
+}
+
+// CrawlDelayer accepts the Crawl-delay directives
+// discovered in robots.txt files.
+type CrawlDelayer interface {
+	SetCrawlDelay(host string, delay time.Duration)
+}
+
+// NewRateLimit throttles loading per host, so that crawling
+// one site quickly does not also overwhelm the third party
+// hosts referenced by its pages. Every wrapped [Loader]
+// shares the same limits. Locations without a host, like
+// file paths, are not throttled.
+func NewRateLimit(c RateLimitConstraints) Middleware {
+	c.Default = c.Default.withDefaults(HostRateLimit{
+		RequestsPerSecond: DefaultRequestsPerSecond,
+		Burst:             DefaultRequestBurst,
+		Concurrency:       DefaultHostConcurrency,
+	})
+	hosts := make(map[string]HostRateLimit, len(c.Hosts))
+	for host, limit := range c.Hosts {
+		hosts[strings.ToLower(host)] = limit.withDefaults(c.Default)
+	}
+	limiter := &rateLimiter{
+		Default: c.Default,
+		Hosts:   hosts,
+		buckets: make(map[string]*tokenBucket),
+	}
+	return MiddlewareFunc(func(l Loader) Loader {
+		if l == nil {
+			panic("nil loader")
+		}
+		return rateLimitLoader{
+			Loader:  l,
+			limiter: limiter,
+		}
+	})
+}
+
+type rateLimiter struct {
+	Default HostRateLimit
+	Hosts   map[string]HostRateLimit
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+func (r *rateLimiter) bucket(host string) *tokenBucket {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, ok := r.buckets[host]
+	if !ok {
+		limit, ok := r.Hosts[host]
+		if !ok {
+			limit = r.Default
+		}
+		b = newTokenBucket(limit)
+		r.buckets[host] = b
+	}
+	return b
+}
+
+func (r *rateLimiter) SetCrawlDelay(host string, delay time.Duration) {
+	r.bucket(strings.ToLower(host)).setCrawlDelay(delay)
+}
+
+type tokenBucket struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    float64
+	tokens   float64
+	last     time.Time
+	slots    chan struct{}
+}
+
+func newTokenBucket(limit HostRateLimit) *tokenBucket {
+	b := &tokenBucket{
+		interval: time.Duration(float64(time.Second) / limit.RequestsPerSecond),
+		burst:    float64(limit.Burst),
+		slots:    make(chan struct{}, limit.Concurrency),
+	}
+	b.tokens = b.burst
+	b.setCrawlDelay(limit.CrawlDelay)
+	return b
+}
+
+func (b *tokenBucket) setCrawlDelay(delay time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if delay > b.interval {
+		b.interval = delay
+		b.burst = 1
+		b.tokens = math.Min(b.tokens, b.burst)
+	}
+}
+
+// acquire takes a concurrency slot and then waits for a token.
+// The slot must be returned with release.
+func (b *tokenBucket) acquire(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case b.slots <- struct{}{}:
+	}
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		if !b.last.IsZero() {
+			b.tokens = math.Min(b.burst, b.tokens+float64(now.Sub(b.last))/float64(b.interval))
+		}
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - b.tokens) * float64(b.interval))
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			b.release()
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (b *tokenBucket) release() {
+	<-b.slots
+}
+
+type rateLimitLoader struct {
+	Loader  Loader
+	limiter *rateLimiter
+}
+
+func (r rateLimitLoader) wait(ctx context.Context, URL string) (*tokenBucket, error) {
+	location, err := url.Parse(URL)
+	if err != nil || location.Host == "" {
+		return nil, nil
+	}
+	b := r.limiter.bucket(strings.ToLower(location.Host))
+	if err = b.acquire(ctx); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (r rateLimitLoader) Load(ctx context.Context, URL string) ([]byte, string, error) {
+	b, err := r.wait(ctx, URL)
+	if err != nil {
+		return nil, "", err
+	}
+	if b != nil {
+		defer b.release()
+	}
+	return r.Loader.Load(ctx, URL)
+}
+
+func (r rateLimitLoader) LoadResponse(ctx context.Context, URL string) (*Response, error) {
+	b, err := r.wait(ctx, URL)
+	if err != nil {
+		return nil, err
+	}
+	if b != nil {
+		defer b.release()
+	}
+	return LoadResponse(ctx, r.Loader, URL)
+}
+
+func (r rateLimitLoader) TraceRedirects(URL string) []Redirect {
+	return TraceRedirects(r.Loader, URL)
+}
+
+func (r rateLimitLoader) RecordPageLink(ctx context.Context, link PageLink) error {
+	return RecordPageLink(ctx, r.Loader, link)
+}
+
+func (r rateLimitLoader) SetCrawlDelay(host string, delay time.Duration) {
+	r.limiter.SetCrawlDelay(host, delay)
+}
@@ -0,0 +1,83 @@
+package pageseo
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingLoader struct {
+	InFlight, Peak atomic.Int32
+}
+
+func (c *countingLoader) Load(ctx context.Context, URL string) ([]byte, string, error) {
+	current := c.InFlight.Add(1)
+	for {
+		peak := c.Peak.Load()
+		if current <= peak || c.Peak.CompareAndSwap(peak, current) {
+			break
+		}
+	}
+	time.Sleep(time.Millisecond * 5)
+	c.InFlight.Add(-1)
+	return []byte("lorem"), "text/plain", nil
+}
+
+func TestRateLimit(t *testing.T) {
+	counter := &countingLoader{}
+	loader := NewRateLimit(RateLimitConstraints{
+		Default: HostRateLimit{
+			RequestsPerSecond: 1000,
+			Burst:             10,
+			Concurrency:       2,
+		},
+		Hosts: map[string]HostRateLimit{
+			"slow.example.com": {
+				RequestsPerSecond: 20,
+				Burst:             1,
+			},
+		},
+	}).WrapLoader(counter)
+
+	wg := sync.WaitGroup{}
+	for range 8 {
+		wg.Go(func() {
+			if _, _, err := loader.Load(t.Context(), "https://fast.example.com/"); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+	if peak := counter.Peak.Load(); peak > 2 {
+		t.Fatal("concurrency cap exceeded:", peak)
+	}
+
+	started := time.Now()
+	for range 3 {
+		if _, _, err := loader.Load(t.Context(), "https://slow.example.com/"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(started); elapsed < time.Millisecond*90 {
+		t.Fatal("slow host was not throttled:", elapsed)
+	}
+
+	loader.(CrawlDelayer).SetCrawlDelay("delayed.example.com", time.Millisecond*50)
+	started = time.Now()
+	for range 2 {
+		if _, _, err := loader.Load(t.Context(), "https://delayed.example.com/"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(started); elapsed < time.Millisecond*45 {
+		t.Fatal("crawl delay was not respected:", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, _, err := loader.Load(ctx, "https://slow.example.com/"); err == nil {
+		t.Fatal("cancelled context did not stop the wait")
+	}
+}
@@ -0,0 +1,178 @@
+package pageseo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dkotik/pageseo/internal"
+)
+
+// ErrRedirectLoop indicates that a location redirects back
+// to one of the locations it was redirected from.
+var ErrRedirectLoop = errors.New("redirect loop")
+
+// maximumRedirects matches the limit of [http.Client].
+const maximumRedirects = 10
+
+// Redirect is a single hop of a redirect chain.
+type Redirect struct {
+	From       string
+	To         string
+	StatusCode int
+}
+
+// RedirectTracer remembers the redirect chains followed
+// by a [Loader]. Loaders implement it to expose the
+// redirects to [NodeTester]s.
+type RedirectTracer interface {
+	// TraceRedirects returns the redirect chain followed
+	// by the most recent load of the location. The final
+	// location is the last hop destination.
+	TraceRedirects(URL string) []Redirect
+}
+
+// TraceRedirects returns the redirect chain followed
+// by the loader, if the loader keeps track of redirects.
+func TraceRedirects(loader Loader, URL string) []Redirect {
+	tracer, ok := loader.(RedirectTracer)
+	if !ok {
+		return nil
+	}
+	return tracer.TraceRedirects(URL)
+}
+
+// redirectLog keeps the chain of the most recent load of
+// each location. Loads of the same location usually follow
+// the same chain. [Response.Redirects] is exact.
+type redirectLog struct {
+	mu     sync.Mutex
+	chains map[string][]Redirect
+}
+
+func (l *redirectLog) TraceRedirects(URL string) []Redirect {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.chains[URL]
+}
+
+func (l *redirectLog) record(URL string, chain []Redirect) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(chain) == 0 {
+		delete(l.chains, URL)
+		return
+	}
+	if l.chains == nil {
+		l.chains = make(map[string][]Redirect)
+	}
+	l.chains[URL] = chain
+}
+
+// followRedirects returns a copy of the client that
+// appends every hop to the chain and stops on loops.
+func followRedirects(client *http.Client, chain *[]Redirect) *http.Client {
+	follower := *client
+	follower.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		hop := Redirect{
+			From: via[len(via)-1].URL.String(),
+			To:   req.URL.String(),
+		}
+		if req.Response != nil {
+			hop.StatusCode = req.Response.StatusCode
+		}
+		*chain = append(*chain, hop)
+		for _, previous := range via {
+			if previous.URL.String() == hop.To {
+				return fmt.Errorf("%w: <%s> leads back to <%s>", ErrRedirectLoop, hop.From, hop.To)
+			}
+		}
+		if client.CheckRedirect != nil {
+			return client.CheckRedirect(req, via)
+		}
+		if len(via) >= maximumRedirects {
+			return fmt.Errorf("stopped after %d redirects", maximumRedirects)
+		}
+		return nil
+	}
+	return &follower
+}
+
+func isTemporaryRedirect(statusCode int) bool {
+	return statusCode == http.StatusFound ||
+		statusCode == http.StatusSeeOther ||
+		statusCode == http.StatusTemporaryRedirect
+}
+
+// isCanonicalizingRedirect returns true for hops that only
+// upgrade the scheme, add or remove the "www." prefix, or
+// change the trailing slash. Such moves are permanent.
+func isCanonicalizingRedirect(hop Redirect) bool {
+	from, err := url.Parse(hop.From)
+	if err != nil {
+		return false
+	}
+	to, err := url.Parse(hop.To)
+	if err != nil {
+		return false
+	}
+	if from.RawQuery != to.RawQuery {
+		return false
+	}
+	fromHost := strings.TrimPrefix(strings.ToLower(from.Host), "www.")
+	toHost := strings.TrimPrefix(strings.ToLower(to.Host), "www.")
+	fromPath := strings.TrimSuffix(from.Path, "/")
+	toPath := strings.TrimSuffix(to.Path, "/")
+	return fromHost == toHost && fromPath == toPath
+}
+
+// describeRedirects lists the hops of the chain.
+func describeRedirects(href string, chain []Redirect) string {
+	hops := make([]string, 0, len(chain)+1)
+	hops = append(hops, href)
+	for _, hop := range chain {
+		hops = append(hops, fmt.Sprintf("%d %s", hop.StatusCode, hop.To))
+	}
+	return strings.Join(hops, " → ")
+}
+
+// validateRedirects reports redirect chains that waste crawl
+// budget, dilute link signals, or weaken transport security.
+// The error is the result of loading the link.
+func validateRedirects(t testing.TB, href string, isInternal bool, chain []Redirect, err error) {
+	if errors.Is(err, ErrRedirectLoop) {
+		if len(chain) == 0 {
+			t.Errorf("link %q never resolves: %v", href, err)
+		} else {
+			t.Errorf("link %q never resolves because of a redirect loop: %s", href, describeRedirects(href, chain))
+		}
+		return
+	}
+	if len(chain) == 0 {
+		return
+	}
+	final := chain[len(chain)-1].To
+	if isInternal {
+		t.Errorf("internal link %q redirects to %q, link to the final location instead", href, final)
+	}
+	if len(chain) > 1 {
+		t.Errorf("link %q goes through %d redirects: %s", href, len(chain), describeRedirects(href, chain))
+	}
+	for _, hop := range chain {
+		if strings.HasPrefix(hop.From, "https:") && strings.HasPrefix(hop.To, "http:") {
+			t.Errorf("redirect from %q to %q downgrades HTTPS to HTTP", hop.From, hop.To)
+		}
+		if !isTemporaryRedirect(hop.StatusCode) {
+			continue
+		}
+		if isCanonicalizingRedirect(hop) {
+			t.Errorf("HTTP %d temporary redirect from %q to %q should be permanent: use 301 or 308", hop.StatusCode, hop.From, hop.To)
+		} else {
+			t.Logf("%s HTTP %d temporary redirect from %q to %q: use 301 or 308 if the move is permanent", internal.WP, hop.StatusCode, hop.From, hop.To)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package pageseo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRedirectTracing(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/old", http.RedirectHandler("/older", http.StatusMovedPermanently))
+	mux.Handle("/older", http.RedirectHandler("/new", http.StatusFound))
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("Lorem ipsum."))
+	})
+	mux.Handle("/loop", http.RedirectHandler("/loop-back", http.StatusFound))
+	mux.Handle("/loop-back", http.RedirectHandler("/loop", http.StatusFound))
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	loader := NewSingleFlightLoader(NewHTTPClient(server.Client(), nil))
+	if _, _, err := loader.Load(t.Context(), server.URL+"/old"); err != nil {
+		t.Fatal(err)
+	}
+	chain := TraceRedirects(loader, server.URL+"/old")
+	if len(chain) != 2 {
+		t.Fatal("unexpected redirect chain:", chain)
+	}
+	if chain[0].StatusCode != http.StatusMovedPermanently || chain[1].StatusCode != http.StatusFound {
+		t.Fatal("unexpected redirect status codes:", chain)
+	}
+	if chain[1].To != server.URL+"/new" {
+		t.Fatal("unexpected final location:", chain[1].To)
+	}
+
+	if _, _, err := loader.Load(t.Context(), server.URL+"/new"); err != nil {
+		t.Fatal(err)
+	}
+	if chain = TraceRedirects(loader, server.URL+"/new"); len(chain) != 0 {
+		t.Fatal("direct load recorded redirects:", chain)
+	}
+
+	if _, _, err := loader.Load(t.Context(), server.URL+"/loop"); !errors.Is(err, ErrRedirectLoop) {
+		t.Fatal("redirect loop was not detected:", err)
+	}
+
+	canceled, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, _, err := loader.Load(canceled, server.URL+"/old"); err == nil {
+		t.Fatal("canceled load succeeded")
+	}
+	if chain = TraceRedirects(loader, server.URL+"/old"); len(chain) != 2 {
+		t.Fatal("failed load erased the redirect chain:", chain)
+	}
+
+	r := testNodes(t, NewAnchorNodeTester(StringConstraints{}), server.URL+"/",
+		`<!DOCTYPE html><html><body><a href="/loop">Loop</a></body></html>`, loader)
+	expected := fmt.Sprintf("redirect loop: %[1]s/loop → 302 %[1]s/loop-back → 302 %[1]s/loop", server.URL)
+	if !r.HasError(expected) || r.HasError("unable to load anchor") {
+		t.Fatalf("redirect loop was not reported as such: %q", r.Errors)
+	}
+}
+
+func TestCanonicalizingRedirect(t *testing.T) {
+	for hop, expected := range map[Redirect]bool{
+		{From: "http://example.com/page", To: "https://example.com/page"}:      true,
+		{From: "https://example.com/page", To: "https://www.example.com/page"}: true,
+		{From: "https://example.com/page", To: "https://example.com/page/"}:    true,
+		{From: "https://example.com/page", To: "https://example.com/login"}:    false,
+		{From: "https://example.com/?a=1", To: "https://example.com/?a=2"}:     false,
+	} {
+		if isCanonicalizingRedirect(hop) != expected {
+			t.Errorf("canonicalizing redirect detection for %+v does not match expected: %v", hop, expected)
+		}
+	}
+}
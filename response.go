@@ -0,0 +1,189 @@
+package pageseo
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Response is a loaded resource together with the
+// transport details that [Loader.Load] discards.
+type Response struct {
+	// URL is the requested location.
+	URL string
+
+	// FinalURL is the location after following redirects.
+	FinalURL string
+
+	// StatusCode is zero when the loader does not
+	// speak HTTP, like the file system loader.
+	StatusCode int
+	Header     http.Header
+	Redirects  []Redirect
+
+	ContentType string
+	Content     []byte
+
+	// ContentEncoding is the transfer compression that was
+	// removed from the content, like "gzip" or "br".
+	ContentEncoding string
+
+	// TransferSize counts the bytes received before
+	// removing the ContentEncoding. It is zero for
+	// loaders that do not speak HTTP.
+	TransferSize int64
+
+	// Duration measures the time spent loading.
+	Duration time.Duration
+
+	// Attempts counts the loads made by the [NewRetry]
+	// middleware. It is zero without the middleware.
+	Attempts int
+
+	// Cached is true when a cache middleware served
+	// the content without downloading it again.
+	Cached bool
+
+	// Truncated is true when a size limit stopped reading
+	// the content, which is incomplete or empty.
+	Truncated bool
+}
+
+// ResponseLoader is a [Loader] that preserves the transport
+// details of every load. [NodeTester]s that need headers or
+// status codes should use [LoadResponse], which works with
+// any [Loader].
+type ResponseLoader interface {
+	LoadResponse(context.Context, string) (*Response, error)
+}
+
+// LoadResponse uses the richer [ResponseLoader] when the
+// loader implements it. Otherwise, it wraps the plain load
+// result into a [Response] that lacks the status code and
+// the headers. The response is returned along with HTTP
+// status errors, but may be nil for other errors.
+func LoadResponse(ctx context.Context, loader Loader, URL string) (*Response, error) {
+	if rl, ok := loader.(ResponseLoader); ok {
+		return rl.LoadResponse(ctx, URL)
+	}
+	started := time.Now()
+	content, contentType, err := loader.Load(ctx, URL)
+	response := &Response{
+		URL:         URL,
+		FinalURL:    URL,
+		Redirects:   TraceRedirects(loader, URL),
+		ContentType: contentType,
+		Content:     content,
+		Duration:    time.Since(started),
+	}
+	if total := len(response.Redirects); total > 0 {
+		response.FinalURL = response.Redirects[total-1].To
+	}
+	return response, err
+}
+
+// fromCache marks a copy of the stored response as cached.
+func fromCache(response *Response) *Response {
+	if response == nil {
+		return nil
+	}
+	cached := *response
+	cached.Cached = true
+	return &cached
+}
+
+// NewResponseLoader upgrades a [Loader] to a [ResponseLoader].
+func NewResponseLoader(loader Loader) ResponseLoader {
+	if loader == nil {
+		panic("nil loader")
+	}
+	if rl, ok := loader.(ResponseLoader); ok {
+		return rl
+	}
+	return responseLoader{Loader: loader}
+}
+
+type responseLoader struct {
+	Loader
+}
+
+func (l responseLoader) LoadResponse(ctx context.Context, URL string) (*Response, error) {
+	return LoadResponse(ctx, l.Loader, URL)
+}
+
+func (l responseLoader) TraceRedirects(URL string) []Redirect {
+	return TraceRedirects(l.Loader, URL)
+}
+
+func (l responseLoader) RecordPageLink(ctx context.Context, link PageLink) error {
+	return RecordPageLink(ctx, l.Loader, link)
+}
+
+// NewLoaderFromResponses adapts a [ResponseLoader] to the
+// [Loader] interface expected by [Middleware]s and
+// [PageTester]s. The result still implements
+// [ResponseLoader].
+func NewLoaderFromResponses(rl ResponseLoader) Loader {
+	if rl == nil {
+		panic("nil response loader")
+	}
+	if loader, ok := rl.(Loader); ok {
+		return loader
+	}
+	return responseAdapter{ResponseLoader: rl}
+}
+
+type responseAdapter struct {
+	ResponseLoader
+}
+
+func (a responseAdapter) Load(ctx context.Context, URL string) ([]byte, string, error) {
+	response, err := a.ResponseLoader.LoadResponse(ctx, URL)
+	if response == nil {
+		return nil, "", err
+	}
+	return response.Content, response.ContentType, err
+}
+
+func (a responseAdapter) TraceRedirects(URL string) []Redirect {
+	if tracer, ok := a.ResponseLoader.(RedirectTracer); ok {
+		return tracer.TraceRedirects(URL)
+	}
+	return nil
+}
+
+func (a responseAdapter) RecordPageLink(ctx context.Context, link PageLink) error {
+	if recorder, ok := a.ResponseLoader.(PageLinkRecorder); ok {
+		return recorder.RecordPageLink(ctx, link)
+	}
+	return nil
+}
+
+// robotsDirectivesWithValues take a value after a colon,
+// which must not be mistaken for a user agent prefix.
+var robotsDirectivesWithValues = []string{
+	"unavailable_after", "max-snippet", "max-image-preview", "max-video-preview",
+}
+
+// isExcludedFromIndex returns true if the <X-Robots-Tag>
+// header forbids indexing for all search engines.
+// Directives addressed to a specific crawler are ignored.
+func isExcludedFromIndex(header http.Header) bool {
+	for _, value := range header.Values("X-Robots-Tag") {
+		if agent, _, ok := strings.Cut(value, ":"); ok {
+			agent = strings.ToLower(strings.TrimSpace(agent))
+			if !strings.ContainsAny(agent, ", ") && !slices.Contains(robotsDirectivesWithValues, agent) {
+				continue
+			}
+		}
+		for _, directive := range strings.Split(value, ",") {
+			switch strings.ToLower(strings.TrimSpace(directive)) {
+			case "noindex", "none":
+				return true
+			}
+		}
+	}
+	return false
+}
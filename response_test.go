@@ -0,0 +1,140 @@
+package pageseo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseLoader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+		_, _ = w.Write([]byte("<p>Lorem ipsum.</p>"))
+	}))
+	t.Cleanup(server.Close)
+
+	// every middleware must pass the response through
+	loader := NewHotSwap(t.Context(), NewSingleFlightLoader(
+		NewRetry(2).WrapLoader(
+			NewCache(func(Resource) {}).WrapLoader(
+				NewSemaphore(
+					NewHTTPClient(server.Client(), nil),
+					NewHTTPClient(server.Client(), nil),
+				),
+			),
+		),
+	), []string{server.URL + "/old"})
+
+	response, err := LoadResponse(t.Context(), loader, server.URL+"/old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatal("unexpected status code:", response.StatusCode)
+	}
+	if response.FinalURL != server.URL+"/new" || len(response.Redirects) != 1 {
+		t.Fatal("redirect was not recorded:", response.FinalURL, response.Redirects)
+	}
+	if response.ContentType != "text/html" {
+		t.Fatal("unexpected content type:", response.ContentType)
+	}
+	if !isExcludedFromIndex(response.Header) {
+		t.Fatal("X-Robots-Tag was not recognized")
+	}
+
+	// plain loaders are adapted
+	plain := NewLoaderFromResponses(NewResponseLoader(skipAllLoadingSingleton))
+	if _, ok := plain.(ResponseLoader); !ok {
+		t.Fatal("adapted loader does not implement ResponseLoader")
+	}
+	if response, _ = LoadResponse(context.Background(), plain, "/lorem"); response.StatusCode != 0 || response.FinalURL != "/lorem" {
+		t.Fatal("unexpected adapted response:", response)
+	}
+}
+
+// tracingLoader hides every optional interface
+// of the loader except for redirect tracing.
+type tracingLoader struct {
+	Loader Loader
+}
+
+func (l tracingLoader) Load(ctx context.Context, URL string) ([]byte, string, error) {
+	return l.Loader.Load(ctx, URL)
+}
+
+func (l tracingLoader) TraceRedirects(URL string) []Redirect {
+	return TraceRedirects(l.Loader, URL)
+}
+
+// responsesOnly hides the [Loader] interface.
+type responsesOnly struct {
+	ResponseLoader
+}
+
+func (r responsesOnly) TraceRedirects(URL string) []Redirect {
+	return TraceRedirects(r.ResponseLoader.(Loader), URL)
+}
+
+func TestResponseThroughAdapters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<p>Lorem ipsum.</p>"))
+	}))
+	t.Cleanup(server.Close)
+	client := NewHTTPClient(server.Client(), nil)
+
+	for name, loader := range map[string]Loader{
+		"data URL":         NewDataURLLoader(client),
+		"response loader":  NewResponseLoader(tracingLoader{Loader: client}).(Loader),
+		"response adapter": NewLoaderFromResponses(responsesOnly{ResponseLoader: client.(ResponseLoader)}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			response, err := LoadResponse(t.Context(), loader, server.URL+"/old")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.FinalURL != server.URL+"/new" || len(response.Redirects) != 1 {
+				t.Fatal("redirect was not recorded:", response.FinalURL, response.Redirects)
+			}
+			if chain := TraceRedirects(loader, server.URL+"/old"); len(chain) != 1 {
+				t.Fatal("redirects were not traced:", chain)
+			}
+		})
+	}
+
+	response, err := LoadResponse(t.Context(), NewDataURLLoader(client), "data:text/plain,lorem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(response.Content) != "lorem" || response.ContentType != "text/plain" {
+		t.Fatalf("unexpected embedded response: %+v", response)
+	}
+}
+
+func TestExcludedFromIndex(t *testing.T) {
+	for value, expected := range map[string]bool{
+		"noindex":                             true,
+		"NONE":                                true,
+		"nofollow, noindex":                   true,
+		"googlebot: noindex":                  false,
+		"unavailable_after: 2025-01-01":       false,
+		"max-snippet: 20, noindex":            true,
+		"index, follow, max-image-preview:50": false,
+	} {
+		header := http.Header{}
+		header.Set("X-Robots-Tag", value)
+		if isExcludedFromIndex(header) != expected {
+			t.Errorf("X-Robots-Tag %q exclusion does not match expected: %v", value, expected)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package pageseo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// StatusError reports a response with an unsuccessful HTTP status code.
+type StatusError struct {
+	StatusCode int
+	Header     http.Header
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP %d error: %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// RetryConstraints configure [NewRetryWithConstraints].
+type RetryConstraints struct {
+	// Attempts is the total number of loads, including the first one.
+	Attempts uint8
+
+	// BaseDelay is the pause before the second attempt. It
+	// doubles with every following attempt up to MaximumDelay.
+	BaseDelay    time.Duration
+	MaximumDelay time.Duration
+
+	// Retryable classifies errors. Defaults to [IsRetryable].
+	Retryable func(error) bool
+}
+
+// NewRetry repeats loads that fail with transient errors up
+// to the limit of attempts, see [NewRetryWithConstraints].
+func NewRetry(limit uint8) Middleware {
+	return NewRetryWithConstraints(RetryConstraints{Attempts: limit})
+}
+
+// NewRetryWithConstraints repeats loads that fail with transient
+// errors, pausing with an exponential backoff and random jitter
+// between attempts. A Retry-After header takes precedence over
+// the backoff, unless it asks for a longer pause than the
+// maximum delay, which ends the retries.
+func NewRetryWithConstraints(c RetryConstraints) Middleware {
+	if c.Attempts == 0 {
+		c.Attempts = DefaultRetryAttempts
+	}
+	if c.Attempts < 2 {
+		panic("retry middleware requires at least two attempts")
+	}
+	if c.BaseDelay <= 0 {
+		c.BaseDelay = DefaultRetryBaseDelay
+	}
+	if c.MaximumDelay <= 0 {
+		c.MaximumDelay = DefaultRetryMaximumDelay
+	}
+	if c.MaximumDelay < c.BaseDelay {
+		panic("maximum retry delay is shorter than the base delay")
+	}
+	if c.Retryable == nil {
+		c.Retryable = IsRetryable
+	}
+	return MiddlewareFunc(func(l Loader) Loader {
+		if l == nil {
+			panic("nil loader")
+		}
+		return retryLoader{
+			AttemptLimit: c.Attempts,
+			BaseDelay:    c.BaseDelay,
+			MaximumDelay: c.MaximumDelay,
+			Retryable:    c.Retryable,
+			Loader:       l,
+		}
+	})
+}
+
+// IsRetryable returns true for transient errors: timeouts,
+// dropped connections, HTTP 408, 429, and 5xx responses,
+// except for 501 and 505, which will not change on their own.
+// [Skip] and context cancellation are never retried. Client
+// timeouts wrap [context.DeadlineExceeded], so the retry loader
+// checks its own context to stop after the caller's deadline.
+func IsRetryable(err error) bool {
+	if err == nil ||
+		errors.Is(err, Skip) ||
+		errors.Is(err, context.Canceled) {
+		return false
+	}
+	var statusError *StatusError
+	if errors.As(err, &statusError) {
+		switch code := statusError.StatusCode; {
+		case code == http.StatusRequestTimeout, code == http.StatusTooManyRequests:
+			return true
+		case code == http.StatusNotImplemented, code == http.StatusHTTPVersionNotSupported:
+			return false
+		default:
+			return code >= 500 && code <= 599
+		}
+	}
+	var netError net.Error
+	if errors.As(err, &netError) && netError.Timeout() {
+		return true
+	}
+	return errors.Is(err, os.ErrDeadlineExceeded) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// parseRetryAfter reads the delay in seconds or
+// the date of the <Retry-After> header.
+func parseRetryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(0, seconds)) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(0, date.Sub(now)), true
+	}
+	return 0, false
+}
+
+type retryLoader struct {
+	AttemptLimit uint8
+	BaseDelay    time.Duration
+	MaximumDelay time.Duration
+	Retryable    func(error) bool
+	Loader       Loader
+}
+
+// backoff doubles the base delay for every attempt and
+// picks a random pause between half and all of it.
+func (r retryLoader) backoff(attempt uint8) time.Duration {
+	delay := r.BaseDelay
+	for i := uint8(1); i < attempt && delay < r.MaximumDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, r.MaximumDelay)
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// pause returns the wait before the next attempt
+// or false if the server asks for a longer one.
+func (r retryLoader) pause(attempt uint8, err error, response *Response) (time.Duration, bool) {
+	var header http.Header
+	if response != nil {
+		header = response.Header
+	}
+	var statusError *StatusError
+	if header == nil && errors.As(err, &statusError) {
+		header = statusError.Header
+	}
+	if header != nil {
+		if delay, ok := parseRetryAfter(header, time.Now()); ok {
+			return delay, delay <= r.MaximumDelay
+		}
+	}
+	return r.backoff(attempt), true
+}
+
+func (r retryLoader) Load(ctx context.Context, url string) ([]byte, string, error) {
+	response, err := r.LoadResponse(ctx, url)
+	if response == nil {
+		return nil, "", err
+	}
+	return response.Content, response.ContentType, err
+}
+
+func (r retryLoader) LoadResponse(ctx context.Context, url string) (response *Response, err error) {
+	var attempt uint8
+	for attempt = 1; ; attempt++ {
+		response, err = LoadResponse(ctx, r.Loader, url)
+		if err == nil || attempt >= r.AttemptLimit || ctx.Err() != nil || !r.Retryable(err) {
+			break
+		}
+		delay, ok := r.pause(attempt, err, response)
+		if !ok {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return response, errors.Join(err, ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+
+	if response != nil {
+		counted := *response // the response may be cached
+		counted.Attempts = int(attempt)
+		response = &counted
+	}
+	if err != nil && attempt > 1 {
+		err = fmt.Errorf("%w (gave up after %d attempts)", err, attempt)
+	}
+	return response, err
+}
+
+func (r retryLoader) TraceRedirects(URL string) []Redirect {
+	return TraceRedirects(r.Loader, URL)
+}
+
+func (r retryLoader) RecordPageLink(ctx context.Context, link PageLink) error {
+	return RecordPageLink(ctx, r.Loader, link)
+}
@@ -0,0 +1,126 @@
+package pageseo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	var unavailable, missing atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/unavailable":
+			if unavailable.Add(1) < 3 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		case "/missing":
+			missing.Add(1)
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("Lorem ipsum."))
+	}))
+	t.Cleanup(server.Close)
+
+	loader := NewRetryWithConstraints(RetryConstraints{
+		Attempts:     4,
+		BaseDelay:    time.Millisecond,
+		MaximumDelay: time.Millisecond * 10,
+	}).WrapLoader(NewHTTPClient(server.Client(), nil))
+
+	response, err := LoadResponse(t.Context(), loader, server.URL+"/unavailable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Attempts != 3 {
+		t.Fatal("unexpected number of attempts:", response.Attempts)
+	}
+
+	_, _, err = loader.Load(t.Context(), server.URL+"/missing")
+	var statusError *StatusError
+	if !errors.As(err, &statusError) || statusError.StatusCode != http.StatusNotFound {
+		t.Fatal("unexpected error:", err)
+	}
+	if missing.Load() != 1 {
+		t.Fatal("permanent error was retried:", missing.Load())
+	}
+
+	skipped := NewRetry(3).WrapLoader(skipAllLoadingSingleton)
+	if response, err = LoadResponse(t.Context(), skipped, "/lorem"); !errors.Is(err, Skip) || response.Attempts != 1 {
+		t.Fatal("skip was retried:", err)
+	}
+}
+
+func TestRetryClientTimeout(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			select { // slower than the client timeout
+			case <-time.After(time.Second):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("Lorem ipsum."))
+	}))
+	t.Cleanup(server.Close)
+
+	client := server.Client()
+	client.Timeout = 50 * time.Millisecond
+	_, _, err := NewHTTPClient(client, nil).Load(t.Context(), server.URL)
+	if err == nil || !IsRetryable(err) {
+		t.Fatal("client timeout is not retryable:", err)
+	}
+
+	requests.Store(0)
+	loader := NewRetryWithConstraints(RetryConstraints{
+		Attempts:  3,
+		BaseDelay: time.Millisecond,
+	}).WrapLoader(NewHTTPClient(client, nil))
+	if _, _, err = loader.Load(t.Context(), server.URL); err != nil {
+		t.Fatal("timed out load was not retried:", err)
+	}
+	if requests.Load() != 2 {
+		t.Fatal("unexpected number of requests:", requests.Load())
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	for err, expected := range map[error]bool{
+		Skip:                         false,
+		context.Canceled:             false,
+		fmt.Errorf("load: %w", Skip): false,
+		&StatusError{StatusCode: http.StatusTooManyRequests}:   true,
+		&StatusError{StatusCode: http.StatusBadGateway}:        true,
+		&StatusError{StatusCode: http.StatusNotImplemented}:    false,
+		&StatusError{StatusCode: http.StatusNotFound}:          false,
+		fmt.Errorf("read: %w", syscall.ECONNRESET):             true,
+		errors.New("unable to parse header <Content-Type> <>"): false,
+	} {
+		if IsRetryable(err) != expected {
+			t.Errorf("retry classification of %q does not match expected: %v", err, expected)
+		}
+	}
+
+	header := http.Header{}
+	header.Set("Retry-After", "120")
+	if delay, ok := parseRetryAfter(header, time.Now()); !ok || delay != time.Minute*2 {
+		t.Fatal("unexpected Retry-After delay:", delay)
+	}
+	now := time.Now()
+	header.Set("Retry-After", now.Add(time.Minute).UTC().Format(http.TimeFormat))
+	if delay, ok := parseRetryAfter(header, now); !ok || delay < time.Second*58 || delay > time.Minute {
+		t.Fatal("unexpected Retry-After date delay:", delay)
+	}
+}
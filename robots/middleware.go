@@ -0,0 +1,244 @@
+package robots
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dkotik/pageseo"
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrDisallowed is returned for locations that robots.txt
+// disallows. It wraps [pageseo.Skip], so the [pageseo.NodeTester]s
+// quietly pass over such resources.
+var ErrDisallowed = fmt.Errorf("%w: disallowed by robots.txt", pageseo.Skip)
+
+// ErrUnreachable is returned when robots.txt cannot be loaded
+// because of a server or network error. RFC 9309 requires
+// crawlers to treat the whole site as disallowed until the file
+// is reachable again. The failure is usually transient, so it
+// is reported instead of silently skipping the site.
+var ErrUnreachable = errors.New("robots.txt is unreachable")
+
+// ErrNotModified is returned when a conditional load of
+// robots.txt finds the file unchanged. The caller that made
+// the load conditional keeps using its stored copy.
+var ErrNotModified = errors.New("robots.txt is not modified")
+
+// Fetcher provides the robots.txt file that governs a location.
+// Loaders wrapped by [NewMiddleware] implement it.
+type Fetcher interface {
+	FetchRobots(context.Context, string) (*File, error)
+}
+
+// Location returns the robots.txt location for the
+// origin of the given location.
+func Location(location string) (string, error) {
+	parsed, err := url.Parse(location)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" || parsed.Host == "" {
+		return "", fmt.Errorf("location <%s> is not a web address", location)
+	}
+	return (&url.URL{
+		Scheme: parsed.Scheme,
+		Host:   parsed.Host,
+		Path:   "/robots.txt",
+	}).String(), nil
+}
+
+// Load fetches and parses the robots.txt file that governs
+// the location. A missing file allows everything, an
+// unreachable one returns [ErrUnreachable], and an unchanged
+// one returns [ErrNotModified].
+func Load(ctx context.Context, loader pageseo.Loader, location string) (*File, error) {
+	if fetcher, ok := loader.(Fetcher); ok {
+		return fetcher.FetchRobots(ctx, location)
+	}
+	robotsURL, err := Location(location)
+	if err != nil {
+		return nil, err
+	}
+	response, err := pageseo.LoadResponse(ctx, loader, robotsURL)
+	switch {
+	case err == nil:
+		return Parse(response.Content), nil
+	case errors.Is(err, pageseo.Skip):
+		return &File{}, nil
+	case response.IsNotModified():
+		return nil, ErrNotModified
+	case response != nil && response.StatusCode >= 400 && response.StatusCode < 500:
+		return &File{}, nil
+	case errors.Is(err, context.Canceled):
+		return nil, err
+	default:
+		return nil, fmt.Errorf("%w: %w", ErrUnreachable, err)
+	}
+}
+
+// SiteMaps returns the sitemap locations declared
+// by the robots.txt file that governs the location.
+func SiteMaps(ctx context.Context, loader pageseo.Loader, location string) ([]string, error) {
+	file, err := Load(ctx, loader, location)
+	if err != nil {
+		return nil, err
+	}
+	return file.SiteMaps, nil
+}
+
+// MiddlewareConstraints configure the robots.txt [pageseo.Middleware].
+type MiddlewareConstraints struct {
+	UserAgent string
+
+	// IsCrawled returns true for the locations that robots.txt
+	// rules apply to. Resources of other sites, like fonts and
+	// scripts served by a CDN, are loaded only to validate the
+	// crawled pages. Nil applies the rules to every location.
+	IsCrawled func(*url.URL) bool
+}
+
+// NewMiddleware fetches robots.txt once per host and
+// returns [ErrDisallowed] for locations the user agent may
+// not load. Crawl-delay directives are passed to wrapped
+// loaders that implement [pageseo.CrawlDelayer], like the
+// one returned by [pageseo.NewRateLimit]. Every wrapped
+// loader shares the same cache. A robots.txt file that is
+// unreachable is not cached, so the next load retries it.
+func NewMiddleware(userAgent string) pageseo.Middleware {
+	return NewMiddlewareWithConstraints(MiddlewareConstraints{UserAgent: userAgent})
+}
+
+// NewMiddlewareWithConstraints also limits the robots.txt
+// rules to the crawled locations.
+func NewMiddlewareWithConstraints(c MiddlewareConstraints) pageseo.Middleware {
+	if strings.TrimSpace(c.UserAgent) == "" {
+		panic("empty user agent")
+	}
+	cache := &cache{
+		files: make(map[string]*File),
+		group: &singleflight.Group{},
+	}
+	return pageseo.MiddlewareFunc(func(l pageseo.Loader) pageseo.Loader {
+		if l == nil {
+			panic("nil loader")
+		}
+		return robotsLoader{
+			UserAgent: c.UserAgent,
+			IsCrawled: c.IsCrawled,
+			Loader:    l,
+			cache:     cache,
+		}
+	})
+}
+
+type cache struct {
+	mu    sync.Mutex
+	files map[string]*File
+	group *singleflight.Group
+}
+
+type robotsLoader struct {
+	UserAgent string
+	IsCrawled func(*url.URL) bool
+	Loader    pageseo.Loader
+	cache     *cache
+}
+
+func (r robotsLoader) FetchRobots(ctx context.Context, location string) (*File, error) {
+	robotsURL, err := Location(location)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.mu.Lock()
+	file, ok := r.cache.files[robotsURL]
+	r.cache.mu.Unlock()
+	if ok {
+		return file, nil
+	}
+
+	result, err, _ := r.cache.group.Do(robotsURL, func() (any, error) {
+		file, err := Load(ctx, r.Loader, robotsURL)
+		if err != nil {
+			return nil, err
+		}
+		r.cache.mu.Lock()
+		r.cache.files[robotsURL] = file
+		r.cache.mu.Unlock()
+
+		if delay := file.Group(r.UserAgent).CrawlDelay; delay > 0 {
+			if delayer, ok := r.Loader.(pageseo.CrawlDelayer); ok {
+				parsed, _ := url.Parse(robotsURL)
+				delayer.SetCrawlDelay(parsed.Host, delay)
+			}
+		}
+		return file, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*File), nil
+}
+
+// check returns [ErrDisallowed] if robots.txt forbids
+// the location. Locations that are not web addresses or
+// not crawled and robots.txt files are always allowed.
+func (r robotsLoader) check(ctx context.Context, location string) error {
+	parsed, err := url.Parse(location)
+	if err != nil || parsed.Scheme != "http" && parsed.Scheme != "https" || parsed.Host == "" {
+		return nil
+	}
+	if parsed.Path == "/robots.txt" {
+		return nil
+	}
+	if r.IsCrawled != nil && !r.IsCrawled(parsed) {
+		return nil
+	}
+	file, err := r.FetchRobots(ctx, location)
+	if err != nil {
+		return err
+	}
+	if !file.Allowed(r.UserAgent, parsed) {
+		return fmt.Errorf("%w: <%s>", ErrDisallowed, location)
+	}
+	return nil
+}
+
+func (r robotsLoader) Load(ctx context.Context, location string) ([]byte, string, error) {
+	if err := r.check(ctx, location); err != nil {
+		return nil, "", err
+	}
+	return r.Loader.Load(ctx, location)
+}
+
+func (r robotsLoader) LoadResponse(ctx context.Context, location string) (*pageseo.Response, error) {
+	if err := r.check(ctx, location); err != nil {
+		return nil, err
+	}
+	return pageseo.LoadResponse(ctx, r.Loader, location)
+}
+
+func (r robotsLoader) TraceRedirects(location string) []pageseo.Redirect {
+	return pageseo.TraceRedirects(r.Loader, location)
+}
+
+func (r robotsLoader) RecordPageLink(ctx context.Context, link pageseo.PageLink) error {
+	return pageseo.RecordPageLink(ctx, r.Loader, link)
+}
+
+func (r robotsLoader) SetCrawlDelay(host string, delay time.Duration) {
+	if delayer, ok := r.Loader.(pageseo.CrawlDelayer); ok {
+		delayer.SetCrawlDelay(host, delay)
+	}
+}
+
+// statusText names the status code for error messages.
+func statusText(code int) string {
+	return fmt.Sprintf("HTTP %d %s", code, http.StatusText(code))
+}
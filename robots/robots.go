@@ -0,0 +1,247 @@
+/*
+Package robots parses robots.txt files according to RFC 9309
+and provides a [pageseo.Middleware] that stops loading
+locations disallowed for a user agent.
+*/
+package robots
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rule allows or disallows the paths matching its pattern.
+// Patterns may contain "*" wildcards and end with "$".
+type Rule struct {
+	Allow   bool
+	Pattern string
+	Line    int
+}
+
+// Group holds the rules that apply to a set of user agents.
+type Group struct {
+	UserAgents []string
+	Rules      []Rule
+	CrawlDelay time.Duration
+}
+
+// SyntaxError describes an invalid robots.txt line.
+type SyntaxError struct {
+	Line    int
+	Message string
+}
+
+func (e SyntaxError) Error() string {
+	return fmt.Sprintf("robots.txt line %d: %s", e.Line, e.Message)
+}
+
+// File is a parsed robots.txt file.
+type File struct {
+	Groups   []Group
+	SiteMaps []string
+
+	// Errors lists the lines that were ignored.
+	Errors []SyntaxError
+}
+
+// MaximumFileSize is the parsing limit required by RFC 9309.
+// Content past the limit is ignored.
+const MaximumFileSize = 500 * 1024
+
+// Parse reads the robots.txt file. Invalid lines are
+// skipped and recorded in [File.Errors].
+func Parse(data []byte) *File {
+	if len(data) > MaximumFileSize {
+		data = data[:MaximumFileSize]
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // byte order mark
+
+	f := &File{}
+	var current *Group
+	expectingRules := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 4096), MaximumFileSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(text, ":")
+		if !ok {
+			f.Errors = append(f.Errors, SyntaxError{Line: line, Message: fmt.Sprintf("missing colon in %q", text)})
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if value == "" {
+				f.Errors = append(f.Errors, SyntaxError{Line: line, Message: "empty user agent"})
+				continue
+			}
+			if current == nil || expectingRules {
+				f.Groups = append(f.Groups, Group{})
+				current = &f.Groups[len(f.Groups)-1]
+				expectingRules = false
+			}
+			current.UserAgents = append(current.UserAgents, value)
+		case "allow", "disallow":
+			if current == nil {
+				f.Errors = append(f.Errors, SyntaxError{Line: line, Message: key + " rule before any user-agent line"})
+				continue
+			}
+			expectingRules = true
+			if value == "" {
+				continue // empty rules match nothing
+			}
+			if value[0] != '/' && value[0] != '*' {
+				f.Errors = append(f.Errors, SyntaxError{Line: line, Message: fmt.Sprintf("%s path %q must start with \"/\"", key, value)})
+				continue
+			}
+			current.Rules = append(current.Rules, Rule{
+				Allow:   key == "allow",
+				Pattern: value,
+				Line:    line,
+			})
+		case "crawl-delay":
+			if current == nil {
+				f.Errors = append(f.Errors, SyntaxError{Line: line, Message: "crawl-delay before any user-agent line"})
+				continue
+			}
+			expectingRules = true
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil || seconds < 0 {
+				f.Errors = append(f.Errors, SyntaxError{Line: line, Message: fmt.Sprintf("invalid crawl-delay %q", value)})
+				continue
+			}
+			current.CrawlDelay = time.Duration(seconds * float64(time.Second))
+		case "sitemap":
+			location, err := url.Parse(value)
+			if err != nil || !location.IsAbs() || location.Host == "" {
+				f.Errors = append(f.Errors, SyntaxError{Line: line, Message: fmt.Sprintf("sitemap %q must be an absolute URL", value)})
+				continue
+			}
+			f.SiteMaps = append(f.SiteMaps, value)
+		case "host", "clean-param", "request-rate", "visit-time", "noindex":
+			// non-standard extensions of some search engines
+			if current != nil {
+				expectingRules = true
+			}
+		default:
+			f.Errors = append(f.Errors, SyntaxError{Line: line, Message: fmt.Sprintf("unknown directive %q", key)})
+		}
+	}
+	return f
+}
+
+// productToken returns the name of the crawler without
+// its version, such as "Googlebot" for "Googlebot/2.1".
+func productToken(userAgent string) string {
+	token, _, _ := strings.Cut(strings.TrimSpace(userAgent), "/")
+	return strings.ToLower(strings.TrimSpace(token))
+}
+
+// Group returns the rules that apply to the user agent.
+// Groups that name the agent are merged. When none do,
+// the groups for "*" apply. The result is empty when
+// there are no matching groups, which allows everything.
+func (f *File) Group(userAgent string) (merged Group) {
+	token := productToken(userAgent)
+	for _, wildcard := range [...]bool{false, true} {
+		for _, group := range f.Groups {
+			for _, agent := range group.UserAgents {
+				matched := agent == "*"
+				if !wildcard {
+					matched = strings.ToLower(agent) == token
+				}
+				if matched {
+					merged.UserAgents = append(merged.UserAgents, agent)
+					merged.Rules = append(merged.Rules, group.Rules...)
+					merged.CrawlDelay = max(merged.CrawlDelay, group.CrawlDelay)
+					break
+				}
+			}
+		}
+		if len(merged.UserAgents) > 0 {
+			return merged
+		}
+	}
+	return merged
+}
+
+// Allowed returns true if the user agent may load the location.
+func (f *File) Allowed(userAgent string, location *url.URL) bool {
+	group := f.Group(userAgent)
+	return group.Allowed(location)
+}
+
+// Allowed returns true if the most specific matching rule
+// allows the location. Allow rules win ties. The robots.txt
+// file itself is always allowed.
+func (g Group) Allowed(location *url.URL) bool {
+	path := location.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if path == "/robots.txt" {
+		return true
+	}
+	if location.RawQuery != "" {
+		path += "?" + location.RawQuery
+	}
+	_, allowed := g.Match(path)
+	return allowed
+}
+
+// Match returns the most specific rule that matches the
+// path, or nil if no rule matches, and whether the path is
+// allowed.
+func (g Group) Match(path string) (*Rule, bool) {
+	var best *Rule
+	for i, rule := range g.Rules {
+		if !matchPattern(rule.Pattern, path) {
+			continue
+		}
+		if best == nil || len(rule.Pattern) > len(best.Pattern) ||
+			len(rule.Pattern) == len(best.Pattern) && rule.Allow && !best.Allow {
+			best = &g.Rules[i]
+		}
+	}
+	return best, best == nil || best.Allow
+}
+
+// matchPattern matches the path prefix against the pattern,
+// where "*" matches any sequence of characters and a
+// trailing "$" anchors the pattern to the end of the path.
+func matchPattern(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = pattern[:len(pattern)-1]
+	}
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	position := len(parts[0])
+	for i, part := range parts[1:] {
+		if anchored && i == len(parts)-2 {
+			// the last part must match the end of the path
+			return strings.HasSuffix(path[position:], part)
+		}
+		found := strings.Index(path[position:], part)
+		if found < 0 {
+			return false
+		}
+		position += found + len(part)
+	}
+	return !anchored || position == len(path)
+}
@@ -0,0 +1,239 @@
+package robots
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dkotik/pageseo"
+)
+
+func TestParse(t *testing.T) {
+	data, err := os.ReadFile("testdata/robots.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := Parse(data)
+	if len(file.Groups) != 3 {
+		t.Fatal("unexpected number of groups:", len(file.Groups))
+	}
+	if len(file.SiteMaps) != 1 || file.SiteMaps[0] != "https://example.com/sitemap.xml" {
+		t.Fatal("unexpected sitemaps:", file.SiteMaps)
+	}
+	if len(file.Errors) != 2 {
+		t.Fatal("unexpected syntax errors:", file.Errors)
+	}
+	if delay := file.Group("pageseo/1.0").CrawlDelay; delay != time.Millisecond*2500 {
+		t.Fatal("unexpected crawl delay:", delay)
+	}
+
+	for _, tc := range []struct {
+		UserAgent string
+		Path      string
+		Allowed   bool
+	}{
+		{UserAgent: "Googlebot/2.1", Path: "/", Allowed: true},
+		{UserAgent: "Googlebot/2.1", Path: "/private/page.html", Allowed: false},
+		{UserAgent: "Googlebot/2.1", Path: "/private/public-page.html", Allowed: true},
+		{UserAgent: "Googlebot/2.1", Path: "/private/public-page.html?lorem", Allowed: false},
+		{UserAgent: "Googlebot/2.1", Path: "/drafts/lorem", Allowed: false},
+		{UserAgent: "Googlebot/2.1", Path: "/app.js", Allowed: false},
+		{UserAgent: "Googlebot/2.1", Path: "/app.json", Allowed: true},
+		{UserAgent: "bingbot", Path: "/drafts/lorem", Allowed: true},
+		{UserAgent: "pageseo", Path: "/lorem", Allowed: false},
+		{UserAgent: "pageseo", Path: "/robots.txt", Allowed: true},
+	} {
+		location, err := url.Parse("https://example.com" + tc.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if file.Allowed(tc.UserAgent, location) != tc.Allowed {
+			t.Errorf("%s access to %q does not match expected: %v", tc.UserAgent, tc.Path, tc.Allowed)
+		}
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	for _, tc := range []struct {
+		Pattern string
+		Path    string
+		Matched bool
+	}{
+		{Pattern: "/", Path: "/lorem", Matched: true},
+		{Pattern: "/lorem", Path: "/lorem-ipsum", Matched: true},
+		{Pattern: "/lorem$", Path: "/lorem-ipsum", Matched: false},
+		{Pattern: "/*.php", Path: "/index.php?lorem", Matched: true},
+		{Pattern: "/*.php$", Path: "/index.php?lorem", Matched: false},
+		{Pattern: "/*/ipsum/*.css$", Path: "/lorem/ipsum/dolor/style.css", Matched: true},
+		{Pattern: "*", Path: "/", Matched: true},
+		{Pattern: "/lorem", Path: "/Lorem", Matched: false},
+	} {
+		if matchPattern(tc.Pattern, tc.Path) != tc.Matched {
+			t.Errorf("pattern %q matching %q does not match expected: %v", tc.Pattern, tc.Path, tc.Matched)
+		}
+	}
+}
+
+type delayRecorder struct {
+	pageseo.Loader
+	Host  string
+	Delay time.Duration
+}
+
+func (d *delayRecorder) SetCrawlDelay(host string, delay time.Duration) {
+	d.Host, d.Delay = host, delay
+}
+
+func TestMiddleware(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "text/plain")
+		if r.URL.Path == "/robots.txt" {
+			_, _ = w.Write([]byte("User-agent: *\nDisallow: /private\nCrawl-delay: 1\nSitemap: " + "http://" + r.Host + "/sitemap.xml\n"))
+			return
+		}
+		_, _ = w.Write([]byte("Lorem ipsum."))
+	}))
+	t.Cleanup(server.Close)
+
+	recorder := &delayRecorder{Loader: pageseo.NewHTTPClient(server.Client(), nil)}
+	loader := NewMiddleware("pageseo").WrapLoader(recorder)
+	if _, _, err := loader.Load(t.Context(), server.URL+"/public"); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err := loader.Load(t.Context(), server.URL+"/private/lorem")
+	if !errors.Is(err, ErrDisallowed) || !errors.Is(err, pageseo.Skip) {
+		t.Fatal("disallowed location was loaded:", err)
+	}
+	if requests != 2 {
+		t.Fatal("robots.txt was not cached:", requests)
+	}
+	if recorder.Delay != time.Second || recorder.Host != strings.TrimPrefix(server.URL, "http://") {
+		t.Fatal("crawl delay was not passed on:", recorder.Host, recorder.Delay)
+	}
+
+	siteMaps, err := SiteMaps(t.Context(), loader, server.URL+"/public")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(siteMaps) != 1 {
+		t.Fatal("unexpected sitemaps:", siteMaps)
+	}
+}
+
+func TestMiddlewareUnreachable(t *testing.T) {
+	available := false
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			requests++
+			if !available {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("Lorem ipsum."))
+	}))
+	t.Cleanup(server.Close)
+
+	loader := NewMiddleware("pageseo").WrapLoader(pageseo.NewHTTPClient(server.Client(), nil))
+	_, _, err := loader.Load(t.Context(), server.URL+"/public")
+	if !errors.Is(err, ErrUnreachable) {
+		t.Fatal("unreachable robots.txt was not reported:", err)
+	}
+	if errors.Is(err, pageseo.Skip) {
+		t.Fatal("unreachable robots.txt must not be skipped quietly:", err)
+	}
+
+	available = true
+	if _, _, err = loader.Load(t.Context(), server.URL+"/public"); err != nil {
+		t.Fatal("robots.txt was not retried:", err)
+	}
+	if requests != 2 {
+		t.Fatal("unexpected robots.txt requests:", requests)
+	}
+}
+
+func TestMiddlewareConditionalLoad(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "text/plain")
+		if r.URL.Path == "/robots.txt" {
+			_, _ = w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+			return
+		}
+		_, _ = w.Write([]byte("Lorem ipsum."))
+	}))
+	t.Cleanup(server.Close)
+
+	client := pageseo.NewHTTPClient(server.Client(), nil)
+	loader := NewMiddleware("pageseo").WrapLoader(client)
+	location := server.URL + "/public"
+	ctx := pageseo.WithValidators(t.Context(), location, pageseo.Validators{ETag: `"v1"`})
+	response, err := pageseo.LoadResponse(ctx, loader, location)
+	if !response.IsNotModified() {
+		t.Fatal("conditional load was not revalidated:", err)
+	}
+	if _, _, err = loader.Load(t.Context(), server.URL+"/private/lorem"); !errors.Is(err, ErrDisallowed) {
+		t.Fatal("validators of the page leaked into the robots.txt load:", err)
+	}
+
+	robotsURL := server.URL + "/robots.txt"
+	ctx = pageseo.WithValidators(t.Context(), robotsURL, pageseo.Validators{ETag: `"v1"`})
+	if _, err = Load(ctx, client, robotsURL); !errors.Is(err, ErrNotModified) {
+		t.Fatal("unchanged robots.txt was not reported:", err)
+	}
+}
+
+func TestMiddlewareIsCrawled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		if r.URL.Path == "/robots.txt" {
+			_, _ = w.Write([]byte("User-agent: *\nDisallow: /\n"))
+			return
+		}
+		_, _ = w.Write([]byte("Lorem ipsum."))
+	}))
+	t.Cleanup(server.Close)
+
+	loader := NewMiddlewareWithConstraints(MiddlewareConstraints{
+		UserAgent: "pageseo",
+		IsCrawled: func(location *url.URL) bool {
+			return strings.HasPrefix(location.Path, "/crawled/")
+		},
+	}).WrapLoader(pageseo.NewHTTPClient(server.Client(), nil))
+	if _, _, err := loader.Load(t.Context(), server.URL+"/cdn/font.woff2"); err != nil {
+		t.Fatal("robots.txt rules were applied to a resource outside of the crawl:", err)
+	}
+	if _, _, err := loader.Load(t.Context(), server.URL+"/crawled/page"); !errors.Is(err, ErrDisallowed) {
+		t.Fatal("disallowed crawled location was loaded:", err)
+	}
+}
+
+func TestBlocksRenderingAssets(t *testing.T) {
+	for pattern, expected := range map[string]bool{
+		"/*.js$":        true,
+		"/*.css":        true,
+		"/assets/*.mjs": true,
+		"/app.json":     false,
+		"/private/":     false,
+	} {
+		if blocksRenderingAssets(pattern) != expected {
+			t.Errorf("rendering asset detection for %q does not match expected: %v", pattern, expected)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package robots
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/dkotik/pageseo"
+	"github.com/dkotik/pageseo/internal"
+)
+
+// renderingCrawlers fetch style sheets and scripts to
+// render pages the way visitors see them.
+var renderingCrawlers = []string{"Googlebot", "Bingbot", "*"}
+
+// Test validates the robots.txt file that governs the location.
+// It reports syntax errors, unreachable sitemaps, and rules that
+// block the style sheets or scripts search engines need for
+// rendering. Resources are the style sheet and script locations
+// used by the site, which must remain allowed.
+func Test(loader pageseo.Loader, location string, resources ...string) func(testing.TB) {
+	return func(t testing.TB) {
+		ctx := t.Context()
+		robotsURL, err := Location(location)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response, err := pageseo.LoadResponse(ctx, loader, robotsURL)
+		if err != nil {
+			switch {
+			case errors.Is(err, pageseo.Skip):
+				return
+			case response != nil && response.StatusCode >= 400 && response.StatusCode < 500:
+				t.Log(internal.WP, "robots.txt is missing:", statusText(response.StatusCode))
+				return
+			case response != nil && response.StatusCode >= 500:
+				t.Fatalf("robots.txt is unreachable, so search engines will not crawl the site: %s", statusText(response.StatusCode))
+			default:
+				t.Fatalf("unable to load robots.txt: %v", err)
+			}
+		}
+		if response.ContentType != "text/plain" {
+			t.Errorf("robots.txt Content-Type must be text/plain, got %q", response.ContentType)
+		}
+		if len(response.Content) > MaximumFileSize {
+			t.Errorf("robots.txt exceeds %d bytes, the rest is ignored", MaximumFileSize)
+		}
+
+		file := Parse(response.Content)
+		for _, syntaxError := range file.Errors {
+			t.Error(syntaxError.Error())
+		}
+		if len(file.Groups) == 0 {
+			t.Log(internal.WP, "robots.txt has no user-agent groups")
+		}
+
+		if len(file.SiteMaps) == 0 {
+			t.Log(internal.WP, "add a Sitemap: directive to robots.txt")
+		}
+		for _, siteMap := range file.SiteMaps {
+			if _, _, err = loader.Load(ctx, siteMap); err != nil && !errors.Is(err, pageseo.Skip) {
+				t.Errorf("sitemap %q listed in robots.txt is unreachable: %v", siteMap, err)
+			}
+		}
+
+		for _, group := range file.Groups {
+			for _, rule := range group.Rules {
+				if !rule.Allow && blocksRenderingAssets(rule.Pattern) {
+					t.Errorf("robots.txt line %d: rule %q blocks style sheets or scripts needed for rendering", rule.Line, rule.Pattern)
+				}
+			}
+		}
+		for _, resource := range resources {
+			parsed, err := url.Parse(resource)
+			if err != nil {
+				t.Errorf("invalid resource location %q: %v", resource, err)
+				continue
+			}
+			for _, agent := range renderingCrawlers {
+				group := file.Group(agent)
+				if rule, allowed := group.Match(parsed.EscapedPath()); !allowed {
+					t.Errorf("robots.txt line %d: rule %q blocks %s from loading %q needed for rendering", rule.Line, rule.Pattern, agent, resource)
+					break
+				}
+			}
+		}
+	}
+}
+
+// blocksRenderingAssets returns true for patterns that
+// target style sheets or scripts, like "/*.js$".
+func blocksRenderingAssets(pattern string) bool {
+	pattern = strings.ToLower(strings.TrimSuffix(pattern, "$"))
+	for _, extension := range [...]string{".css", ".js", ".mjs"} {
+		if strings.HasSuffix(pattern, extension) || strings.Contains(pattern, "*"+extension) {
+			return true
+		}
+	}
+	return false
+}
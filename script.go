@@ -48,16 +48,21 @@ func (s script) TestNode(t testing.TB, origin *url.URL, node *html.Node, loader
 	}
 
 	if source != "" {
-		script, contentType, err := loader.Load(
-			t.Context(),
-			joinRelativePath(origin, source),
-		)
+		location := joinRelativePath(origin, source)
+		validateActiveContent(t, origin, "<script[src]>", location)
+		response, err := LoadResponse(t.Context(), loader, location)
 		if err != nil {
 			if errors.Is(err, Skip) {
 				return
 			}
 			t.Errorf("unable to load script %q: %v", source, err)
 		}
+		var script []byte
+		var contentType string
+		if response != nil {
+			script, contentType = response.Content, response.ContentType
+			validateCompression(t, location, response)
+		}
 
 		switch contentType {
 		case "":
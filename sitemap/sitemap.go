@@ -1,7 +1,10 @@
 package sitemap
 
 import (
+	"context"
 	"encoding/xml"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/dkotik/pageseo"
@@ -56,3 +59,44 @@ func Test(loader pageseo.Loader, URL string) func(testing.TB) {
 		}
 	}
 }
+
+// Locations loads the sitemap at URL and returns the page
+// locations it lists. Sitemap indexes are followed up to
+// a depth of three nested sitemaps.
+func Locations(ctx context.Context, loader pageseo.Loader, URL string) ([]string, error) {
+	return collectLocations(ctx, loader, URL, 3)
+}
+
+func collectLocations(ctx context.Context, loader pageseo.Loader, URL string, depth int) (locations []string, err error) {
+	if depth == 0 {
+		return nil, fmt.Errorf("sitemap index <%s> is nested too deeply", URL)
+	}
+	data, _, err := loader.Load(ctx, URL)
+	if err != nil {
+		return nil, err
+	}
+	var index SiteMapIndex
+	if err = xml.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("unable to decode sitemap <%s>: %w", URL, err)
+	}
+	if len(index.SiteMaps) == 0 {
+		var sitemap URLSet
+		if err = xml.Unmarshal(data, &sitemap); err != nil {
+			return nil, fmt.Errorf("unable to decode sitemap <%s>: %w", URL, err)
+		}
+		for _, location := range sitemap.URLs {
+			if loc := strings.TrimSpace(location.Loc); loc != "" {
+				locations = append(locations, loc)
+			}
+		}
+		return locations, nil
+	}
+	for _, nested := range index.SiteMaps {
+		found, err := collectLocations(ctx, loader, strings.TrimSpace(nested.Loc), depth-1)
+		if err != nil {
+			return nil, err
+		}
+		locations = append(locations, found...)
+	}
+	return locations, nil
+}
@@ -11,3 +11,17 @@ func TestSiteMapValidation(t *testing.T) {
 	loader := pageseo.NewFS(os.DirFS("testdata"))
 	Test(loader, "single.xml")(t)
 }
+
+func TestSiteMapLocations(t *testing.T) {
+	loader := pageseo.NewFS(os.DirFS("testdata"))
+	locations, err := Locations(t.Context(), loader, "single.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locations) != 5 {
+		t.Fatal("unexpected number of locations:", len(locations))
+	}
+	if locations[0] != "http://www.example.com/" {
+		t.Fatal("unexpected first location:", locations[0])
+	}
+}
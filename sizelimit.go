@@ -0,0 +1,144 @@
+package pageseo
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSizeLimits keep only the headers of media files,
+// which are too large to download for checking the link.
+var DefaultSizeLimits = map[string]int64{
+	"video/*": 0,
+	"audio/*": 0,
+}
+
+// SizeLimitConstraints set byte budgets for
+// loaded content by content type.
+type SizeLimitConstraints struct {
+	// Default is the budget of the content types missing
+	// from ContentTypes. Defaults to
+	// [DefaultMaximumResponseBytes].
+	Default int64
+
+	// ContentTypes map media types, or wildcards like
+	// "video/*", to budgets. Zero budget keeps only the
+	// headers, while negative budget is unlimited.
+	// Defaults to [DefaultSizeLimits].
+	ContentTypes map[string]int64
+}
+
+func (c SizeLimitConstraints) withDefaults() SizeLimitConstraints {
+	if c.Default == 0 {
+		c.Default = DefaultMaximumResponseBytes
+	}
+	if c.ContentTypes == nil {
+		c.ContentTypes = DefaultSizeLimits
+	}
+	return c
+}
+
+// Budget returns the byte budget of the content type.
+func (c SizeLimitConstraints) Budget(contentType string) int64 {
+	if contentType == "" {
+		return c.Default
+	}
+	if budget, ok := c.ContentTypes[contentType]; ok {
+		return budget
+	}
+	major, _, _ := strings.Cut(contentType, "/")
+	if budget, ok := c.ContentTypes[major+"/*"]; ok {
+		return budget
+	}
+	if budget, ok := c.ContentTypes["*/*"]; ok {
+		return budget
+	}
+	return c.Default
+}
+
+// guessContentType infers the content type from the
+// file extension of the location, if there is one.
+func guessContentType(location string) string {
+	parsed, err := url.Parse(location)
+	if err != nil {
+		return ""
+	}
+	contentType, _, err := mime.ParseMediaType(mime.TypeByExtension(path.Ext(parsed.Path)))
+	if err != nil {
+		return ""
+	}
+	return contentType
+}
+
+// isPartialContent returns true if the <Content-Range>
+// header reports more content than was received.
+func isPartialContent(header http.Header, received int) bool {
+	_, total, ok := strings.Cut(header.Get("Content-Range"), "/")
+	if !ok {
+		return false
+	}
+	size, err := strconv.Atoi(strings.TrimSpace(total))
+	return err != nil || size > received // unknown size is "*"
+}
+
+// NewSizeLimit truncates the content that exceeds the byte
+// budget of its content type and marks the response as
+// [Response.Truncated]. It bounds the memory held by the
+// middleware around it, like caches. Use
+// [NewHTTPClientWithSizeLimit] to avoid the download.
+func NewSizeLimit(c SizeLimitConstraints) Middleware {
+	c = c.withDefaults()
+	return MiddlewareFunc(func(l Loader) Loader {
+		if l == nil {
+			panic("nil loader")
+		}
+		return sizeLimitLoader{Loader: l, SizeLimitConstraints: c}
+	})
+}
+
+type sizeLimitLoader struct {
+	Loader Loader
+	SizeLimitConstraints
+}
+
+func (l sizeLimitLoader) Load(ctx context.Context, URL string) ([]byte, string, error) {
+	response, err := l.LoadResponse(ctx, URL)
+	if response == nil {
+		return nil, "", err
+	}
+	return response.Content, response.ContentType, err
+}
+
+func (l sizeLimitLoader) LoadResponse(ctx context.Context, URL string) (*Response, error) {
+	response, err := LoadResponse(ctx, l.Loader, URL)
+	if response == nil {
+		return nil, err
+	}
+	budget := l.Budget(response.ContentType)
+	if budget < 0 || int64(len(response.Content)) <= budget {
+		return response, err
+	}
+	truncated := *response
+	truncated.Content = response.Content[:budget:budget]
+	truncated.Truncated = true
+	return &truncated, err
+}
+
+func (l sizeLimitLoader) TraceRedirects(URL string) []Redirect {
+	return TraceRedirects(l.Loader, URL)
+}
+
+func (l sizeLimitLoader) RecordPageLink(ctx context.Context, link PageLink) error {
+	return RecordPageLink(ctx, l.Loader, link)
+}
+
+func (l sizeLimitLoader) SetCrawlDelay(host string, delay time.Duration) {
+	if delayer, ok := l.Loader.(CrawlDelayer); ok {
+		delayer.SetCrawlDelay(host, delay)
+	}
+}
@@ -0,0 +1,122 @@
+package pageseo
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dkotik/pageseo/internal"
+)
+
+func TestSizeLimit(t *testing.T) {
+	methods := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods[r.URL.Path] = r.Method + " " + r.Header.Get("Range")
+		switch r.URL.Path {
+		case "/video.mp4":
+			w.Header().Set("Content-Type", "video/mp4")
+			w.Header().Set("Content-Length", "2000000000")
+		case "/clip.webm":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.Header().Set("Content-Type", "video/webm")
+			w.Header().Set("Content-Range", "bytes 0-0/1000")
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write([]byte{0})
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write(bytes.Repeat([]byte("a"), 100))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	loader := NewHTTPClientWithSizeLimit(server.Client(), nil, SizeLimitConstraints{
+		Default: 10,
+	})
+	cases := []struct {
+		Path        string
+		Method      string
+		ContentType string
+		Length      int
+	}{
+		{Path: "/video.mp4", Method: "HEAD ", ContentType: "video/mp4", Length: 0},
+		{Path: "/clip.webm", Method: "GET bytes=0-0", ContentType: "video/webm", Length: 0},
+		{Path: "/page.html", Method: "GET ", ContentType: "text/html", Length: 10},
+	}
+	for _, c := range cases {
+		t.Run(c.Path, func(t *testing.T) {
+			response, err := LoadResponse(t.Context(), loader, server.URL+c.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if methods[c.Path] != c.Method {
+				t.Fatalf("requested with %q instead of %q", methods[c.Path], c.Method)
+			}
+			if response.ContentType != c.ContentType || len(response.Content) != c.Length || !response.Truncated {
+				t.Fatalf("unexpected response: %s %d bytes, truncated: %v", response.ContentType, len(response.Content), response.Truncated)
+			}
+		})
+	}
+}
+
+func TestSizeLimitMiddleware(t *testing.T) {
+	limit := NewSizeLimit(SizeLimitConstraints{
+		ContentTypes: map[string]int64{"video/*": 0, "text/html": -1},
+	})
+	videos := limit.WrapLoader(internal.NewMockLoader(func(string) (string, error) {
+		return "movie", nil
+	}, "video/mp4"))
+	pages := limit.WrapLoader(internal.NewMockLoader(func(string) (string, error) {
+		return "<!DOCTYPE html>", nil
+	}, "text/html"))
+
+	response, err := LoadResponse(t.Context(), videos, "video.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Content) != 0 || !response.Truncated {
+		t.Fatal("media content was not truncated:", string(response.Content))
+	}
+	response, err = LoadResponse(t.Context(), pages, "index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(response.Content) != "<!DOCTYPE html>" || response.Truncated {
+		t.Fatal("unlimited content was truncated:", string(response.Content))
+	}
+}
+
+func TestFSSizeLimit(t *testing.T) {
+	movie := append([]byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom"), make([]byte, 4096)...)
+	loader := NewFSWithSizeLimit(fstest.MapFS{
+		"video.mp4":  {Data: movie},
+		"index.html": {Data: bytes.Repeat([]byte("<!DOCTYPE html>"), 100)},
+	}, SizeLimitConstraints{Default: 1000})
+
+	response, err := LoadResponse(t.Context(), loader, "video.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.ContentType != "video/mp4" || len(response.Content) != 0 || !response.Truncated {
+		t.Fatalf("unexpected response: %s %d bytes, truncated: %v", response.ContentType, len(response.Content), response.Truncated)
+	}
+	response, err = LoadResponse(t.Context(), loader, "index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.ContentType != "text/html" || len(response.Content) != 1000 || !response.Truncated {
+		t.Fatalf("unexpected response: %s %d bytes, truncated: %v", response.ContentType, len(response.Content), response.Truncated)
+	}
+
+	data, ct, err := NewFS(fstest.MapFS{"video.mp4": {Data: movie}}).Load(t.Context(), "video.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct != "video/mp4" || !bytes.Equal(data, movie) {
+		t.Fatal("unlimited file was not loaded in full:", ct, len(data))
+	}
+}
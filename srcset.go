@@ -0,0 +1,254 @@
+package pageseo
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ImageCandidate is a single entry of an image source set.
+// Width is set by a "w" descriptor, Density by an "x" descriptor.
+// A candidate without descriptors has neither and implies 1x density.
+type ImageCandidate struct {
+	URL     string
+	Width   int
+	Density float64
+	Height  int
+}
+
+func isSourceSetSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', '\f':
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseSourceSet parses an <img[srcset]> or <source[srcset]>
+// attribute according to the HTML specification. Candidates with
+// invalid descriptors are dropped. The returned error joins every
+// problem encountered along the way, but the valid candidates are
+// returned regardless.
+func ParseSourceSet(srcset string) (candidates []ImageCandidate, err error) {
+	var errs []error
+	position, length := 0, len(srcset)
+	for {
+		for position < length && (isSourceSetSpace(srcset[position]) || srcset[position] == ',') {
+			position++
+		}
+		if position >= length {
+			return candidates, errors.Join(errs...)
+		}
+
+		start := position
+		for position < length && !isSourceSetSpace(srcset[position]) {
+			position++
+		}
+		location := srcset[start:position]
+		var descriptors []string
+		if trimmed := strings.TrimRight(location, ","); trimmed != location {
+			if len(location)-len(trimmed) > 1 {
+				errs = append(errs, fmt.Errorf("candidate %q is followed by extra commas", trimmed))
+			}
+			location = trimmed
+		} else {
+			descriptors, position = tokenizeSourceSetDescriptors(srcset, position)
+		}
+		if location == "" {
+			continue
+		}
+
+		candidate, err := parseImageCandidateDescriptors(location, descriptors)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+}
+
+func tokenizeSourceSetDescriptors(srcset string, position int) (descriptors []string, _ int) {
+	for position < len(srcset) && isSourceSetSpace(srcset[position]) {
+		position++
+	}
+	current := strings.Builder{}
+	inParens := false
+	flush := func() {
+		if current.Len() > 0 {
+			descriptors = append(descriptors, current.String())
+			current.Reset()
+		}
+	}
+	for ; position < len(srcset); position++ {
+		c := srcset[position]
+		switch {
+		case inParens:
+			if c == ')' {
+				inParens = false
+			}
+			_ = current.WriteByte(c)
+		case isSourceSetSpace(c):
+			flush()
+		case c == ',':
+			flush()
+			return descriptors, position + 1
+		case c == '(':
+			inParens = true
+			_ = current.WriteByte(c)
+		default:
+			_ = current.WriteByte(c)
+		}
+	}
+	flush()
+	return descriptors, position
+}
+
+func parseImageCandidateDescriptors(location string, descriptors []string) (c ImageCandidate, err error) {
+	c.URL = location
+	for _, descriptor := range descriptors {
+		value := descriptor[:len(descriptor)-1]
+		switch descriptor[len(descriptor)-1] {
+		case 'w':
+			if c.Width != 0 || c.Density != 0 {
+				return c, fmt.Errorf("candidate %q has conflicting descriptor %q", location, descriptor)
+			}
+			c.Width, err = strconv.Atoi(value)
+			if err != nil || c.Width <= 0 || strings.HasPrefix(value, "+") {
+				return c, fmt.Errorf("candidate %q width descriptor %q is not a positive integer", location, descriptor)
+			}
+		case 'x':
+			if c.Width != 0 || c.Density != 0 || c.Height != 0 {
+				return c, fmt.Errorf("candidate %q has conflicting descriptor %q", location, descriptor)
+			}
+			c.Density, err = strconv.ParseFloat(value, 64)
+			if err != nil || c.Density <= 0 || strings.HasPrefix(value, "+") {
+				return c, fmt.Errorf("candidate %q density descriptor %q is not a positive number", location, descriptor)
+			}
+		case 'h':
+			if c.Height != 0 || c.Density != 0 {
+				return c, fmt.Errorf("candidate %q has conflicting descriptor %q", location, descriptor)
+			}
+			c.Height, err = strconv.Atoi(value)
+			if err != nil || c.Height <= 0 || strings.HasPrefix(value, "+") {
+				return c, fmt.Errorf("candidate %q height descriptor %q is not a positive integer", location, descriptor)
+			}
+		default:
+			return c, fmt.Errorf("candidate %q has unknown descriptor %q", location, descriptor)
+		}
+	}
+	if c.Height != 0 && c.Width == 0 {
+		return c, fmt.Errorf("candidate %q height descriptor requires a width descriptor", location)
+	}
+	return c, nil
+}
+
+// SourceSize is a single entry of an image [sizes] attribute.
+// The last entry usually has no media condition.
+type SourceSize struct {
+	MediaCondition string
+	Length         string
+}
+
+// ParseSizes parses an <img[sizes]> or <source[sizes]>
+// attribute. Commas inside parentheses, such as in calc()
+// expressions, do not separate entries.
+func ParseSizes(sizes string) (result []SourceSize, err error) {
+	var errs []error
+	for _, entry := range splitOutsideParens(sizes, ',') {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			errs = append(errs, errors.New("empty source size entry"))
+			continue
+		}
+		size := SourceSize{Length: entry}
+		if i := lastSpaceOutsideParens(entry); i > -1 {
+			size.MediaCondition = strings.TrimSpace(entry[:i])
+			size.Length = entry[i+1:]
+		}
+		if !isValidSourceSizeLength(size.Length) {
+			errs = append(errs, fmt.Errorf("source size %q is not a valid length", entry))
+			continue
+		}
+		if size.MediaCondition != "" && !strings.HasPrefix(size.MediaCondition, "(") &&
+			!strings.HasPrefix(strings.ToLower(size.MediaCondition), "not") {
+			errs = append(errs, fmt.Errorf("source size %q media condition must be in parentheses", entry))
+			continue
+		}
+		result = append(result, size)
+	}
+	for i, size := range result {
+		if size.MediaCondition == "" && i != len(result)-1 {
+			errs = append(errs, fmt.Errorf("source size %q without a media condition hides the entries after it", size.Length))
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+func splitOutsideParens(s string, separator byte) (parts []string) {
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case separator:
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func lastSpaceOutsideParens(s string) int {
+	depth := 0
+	for i := len(s) - 1; i >= 0; i-- {
+		switch {
+		case s[i] == ')':
+			depth++
+		case s[i] == '(':
+			depth--
+		case depth == 0 && isSourceSetSpace(s[i]):
+			return i
+		}
+	}
+	return -1
+}
+
+func isValidSourceSizeLength(length string) bool {
+	length = strings.ToLower(length)
+	switch {
+	case length == "auto", length == "0":
+		return true
+	case strings.HasSuffix(length, ")"):
+		for _, function := range []string{"calc(", "min(", "max(", "clamp("} {
+			if strings.HasPrefix(length, function) {
+				return true
+			}
+		}
+		return false
+	case strings.HasSuffix(length, "%"):
+		return false // percentages are not allowed
+	}
+	i := strings.IndexFunc(length, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 1 {
+		return false
+	}
+	value, err := strconv.ParseFloat(length[:i], 64)
+	if err != nil || value < 0 {
+		return false
+	}
+	switch length[i:] {
+	case "px", "em", "rem", "ex", "ch", "vw", "vh", "vmin", "vmax",
+		"svw", "svh", "lvw", "lvh", "dvw", "dvh", "cm", "mm", "in", "pt", "pc", "q":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,93 @@
+package pageseo
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseSourceSet(t *testing.T) {
+	tcs := []struct {
+		SourceSet  string
+		Candidates []ImageCandidate
+		IsValid    bool
+	}{
+		{
+			SourceSet: "small.jpg 480w, large.jpg 1080w",
+			Candidates: []ImageCandidate{
+				{URL: "small.jpg", Width: 480},
+				{URL: "large.jpg", Width: 1080},
+			},
+			IsValid: true,
+		},
+		{
+			SourceSet: "image.png, image@2x.png 2x",
+			Candidates: []ImageCandidate{
+				{URL: "image.png"},
+				{URL: "image@2x.png", Density: 2},
+			},
+			IsValid: true,
+		},
+		{
+			SourceSet: "/cdn/w_400,h_300/image.jpg 400w,\n  /cdn/w_800,h_600/image.jpg 800w 600h",
+			Candidates: []ImageCandidate{
+				{URL: "/cdn/w_400,h_300/image.jpg", Width: 400},
+				{URL: "/cdn/w_800,h_600/image.jpg", Width: 800, Height: 600},
+			},
+			IsValid: true,
+		},
+		{
+			SourceSet: "a.jpg 1x, b.jpg 2q, c.jpg 2x 300w",
+			Candidates: []ImageCandidate{
+				{URL: "a.jpg", Density: 1},
+			},
+			IsValid: false,
+		},
+		{
+			SourceSet: "a.jpg,, b.jpg -100w",
+			Candidates: []ImageCandidate{
+				{URL: "a.jpg"},
+			},
+			IsValid: false,
+		},
+	}
+
+	for _, tc := range tcs {
+		candidates, err := ParseSourceSet(tc.SourceSet)
+		if (err == nil) != tc.IsValid {
+			t.Fatalf("unexpected validation result for %q: %v", tc.SourceSet, err)
+		}
+		if !slices.Equal(candidates, tc.Candidates) {
+			t.Log("  result:", candidates)
+			t.Log("expected:", tc.Candidates)
+			t.Fatalf("unexpected candidates for %q", tc.SourceSet)
+		}
+	}
+}
+
+func TestParseSizes(t *testing.T) {
+	sizes, err := ParseSizes("(max-width: 600px) calc(100vw - 2rem), (min-width: 1200px) 50vw, 800px")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []SourceSize{
+		{MediaCondition: "(max-width: 600px)", Length: "calc(100vw - 2rem)"},
+		{MediaCondition: "(min-width: 1200px)", Length: "50vw"},
+		{Length: "800px"},
+	}
+	if !slices.Equal(sizes, expected) {
+		t.Log("  result:", sizes)
+		t.Log("expected:", expected)
+		t.Fatal("unexpected source sizes")
+	}
+
+	for _, invalid := range []string{
+		"100%",
+		"100vw, (max-width: 600px) 50vw",
+		"max-width: 600px 50vw",
+		"(max-width: 600px) wide",
+	} {
+		if _, err = ParseSizes(invalid); err == nil {
+			t.Fatalf("invalid sizes %q were accepted", invalid)
+		}
+	}
+}
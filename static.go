@@ -0,0 +1,176 @@
+package pageseo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// StaticSiteConstraints configure [NewStaticSiteLoader].
+type StaticSiteConstraints struct {
+	// BaseURL is the location where the site is published.
+	// Relative locations are resolved against it, while
+	// locations on other hosts return [Skip].
+	BaseURL string
+
+	// IndexPage is served for directory locations.
+	// Defaults to [DefaultIndexPage].
+	IndexPage string
+
+	// NotFoundPage is served with status 404 for missing
+	// locations. Defaults to [DefaultNotFoundPage].
+	NotFoundPage string
+}
+
+// NewStaticSiteLoader serves a built static site, like the
+// output of Hugo, Jekyll, or Astro, the way a static web
+// server would. Directory locations serve the index page
+// and clean URLs like "/about" serve "about.html". Content
+// types are chosen by file extension.
+func NewStaticSiteLoader(fsys fs.FS, c StaticSiteConstraints) Loader {
+	if fsys == nil {
+		panic("nil file system")
+	}
+	base, err := url.Parse(c.BaseURL)
+	if err != nil || !base.IsAbs() {
+		panic(fmt.Sprintf("invalid base URL %q", c.BaseURL))
+	}
+	if c.IndexPage == "" {
+		c.IndexPage = DefaultIndexPage
+	}
+	if c.NotFoundPage == "" {
+		c.NotFoundPage = DefaultNotFoundPage
+	}
+	return staticSiteLoader{
+		FS:           fsys,
+		Base:         base,
+		IndexPage:    c.IndexPage,
+		NotFoundPage: strings.TrimPrefix(c.NotFoundPage, "/"),
+	}
+}
+
+type staticSiteLoader struct {
+	FS           fs.FS
+	Base         *url.URL
+	IndexPage    string
+	NotFoundPage string
+}
+
+func (s staticSiteLoader) Load(ctx context.Context, URL string) ([]byte, string, error) {
+	response, err := s.LoadResponse(ctx, URL)
+	if response == nil {
+		return nil, "", err
+	}
+	return response.Content, response.ContentType, err
+}
+
+func (s staticSiteLoader) TraceRedirects(URL string) []Redirect {
+	response, _ := s.LoadResponse(context.Background(), URL)
+	if response == nil {
+		return nil
+	}
+	return response.Redirects
+}
+
+func (s staticSiteLoader) LoadResponse(_ context.Context, URL string) (*Response, error) {
+	started := time.Now()
+	location, err := s.Base.Parse(URL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse <%s>: %w", URL, err)
+	}
+	if location.Host != s.Base.Host {
+		return nil, Skip
+	}
+	location.Fragment = ""
+	response := &Response{
+		URL:      URL,
+		FinalURL: location.String(),
+	}
+
+	name, found, isDirectory := s.resolve(location.Path)
+	if isDirectory {
+		// static servers add the trailing slash
+		redirected := *location
+		redirected.Path += "/"
+		response.Redirects = []Redirect{{
+			From:       location.String(),
+			To:         redirected.String(),
+			StatusCode: http.StatusMovedPermanently,
+		}}
+		response.FinalURL = redirected.String()
+	}
+	response.StatusCode = http.StatusOK
+	if !found {
+		response.StatusCode = http.StatusNotFound
+		name = s.NotFoundPage
+	}
+
+	response.Content, err = fs.ReadFile(s.FS, name)
+	switch {
+	case err == nil:
+		response.ContentType = contentTypeByExtension(name, response.Content)
+	case found || !errors.Is(err, fs.ErrNotExist):
+		return nil, fmt.Errorf("unable to load <%s>: %w", URL, err)
+	default:
+		response.ContentType = "text/plain" // no custom 404 page
+		response.Content = []byte(http.StatusText(http.StatusNotFound))
+	}
+	response.Header = http.Header{"Content-Type": []string{response.ContentType}}
+	response.Duration = time.Since(started)
+	if !found {
+		return response, &StatusError{
+			StatusCode: response.StatusCode,
+			Header:     response.Header,
+		}
+	}
+	return response, nil
+}
+
+// resolve maps the URL path onto a file. Directories
+// requested without the trailing slash are reported,
+// so that the caller can record the redirect.
+func (s staticSiteLoader) resolve(urlPath string) (name string, found, isDirectory bool) {
+	relative, ok := strings.CutPrefix(urlPath, strings.TrimSuffix(s.Base.Path, "/"))
+	if !ok {
+		return urlPath, false, false // outside of the site
+	}
+	name = strings.TrimPrefix(path.Clean("/"+relative), "/")
+	if strings.HasSuffix(relative, "/") || name == "" {
+		return path.Join(name, s.IndexPage), s.isFile(path.Join(name, s.IndexPage)), false
+	}
+	if s.isFile(name) {
+		return name, true, false
+	}
+	if index := path.Join(name, s.IndexPage); s.isFile(index) {
+		return index, true, true
+	}
+	if path.Ext(name) == "" && s.isFile(name+".html") {
+		return name + ".html", true, false // clean URL
+	}
+	return name, false, false
+}
+
+func (s staticSiteLoader) isFile(name string) bool {
+	info, err := fs.Stat(s.FS, name)
+	return err == nil && !info.IsDir()
+}
+
+// contentTypeByExtension prefers the file extension, because
+// content sniffing reports style sheets and scripts as text.
+func contentTypeByExtension(name string, content []byte) string {
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
+	if parsed, _, err := mime.ParseMediaType(contentType); err == nil {
+		return parsed
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,73 @@
+package pageseo
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"testing/fstest"
+)
+
+func TestStaticSiteLoader(t *testing.T) {
+	site := fstest.MapFS{
+		"index.html":       {Data: []byte("<!DOCTYPE html><title>home</title>")},
+		"about/index.html": {Data: []byte("<!DOCTYPE html><title>about</title>")},
+		"contact.html":     {Data: []byte("<!DOCTYPE html><title>contact</title>")},
+		"css/site.css":     {Data: []byte("body { color: black; }")},
+		"js/site.js":       {Data: []byte("console.log('hi')")},
+		"missing.html":     {Data: []byte("<!DOCTYPE html><title>not found</title>")},
+	}
+	loader := NewStaticSiteLoader(site, StaticSiteConstraints{
+		BaseURL:      "https://example.com/docs/",
+		NotFoundPage: "missing.html",
+	})
+
+	cases := []struct {
+		URL         string
+		Title       string
+		ContentType string
+	}{
+		{URL: "https://example.com/docs/", Title: "home", ContentType: "text/html"},
+		{URL: "https://example.com/docs/about/", Title: "about", ContentType: "text/html"},
+		{URL: "/docs/contact", Title: "contact", ContentType: "text/html"},
+		{URL: "contact.html#form", Title: "contact", ContentType: "text/html"},
+		{URL: "css/site.css", ContentType: "text/css"},
+		{URL: "js/site.js", ContentType: "text/javascript"},
+	}
+	for _, c := range cases {
+		t.Run(c.URL, func(t *testing.T) {
+			data, ct, err := loader.Load(t.Context(), c.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ct != c.ContentType {
+				t.Fatal("unexpected content type:", ct)
+			}
+			if c.Title != "" && string(data) != "<!DOCTYPE html><title>"+c.Title+"</title>" {
+				t.Fatal("unexpected page:", string(data))
+			}
+		})
+	}
+
+	response, err := LoadResponse(t.Context(), loader, "/docs/about")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.FinalURL != "https://example.com/docs/about/" || len(response.Redirects) != 1 {
+		t.Fatal("directory without trailing slash was not redirected:", response.FinalURL, response.Redirects)
+	}
+
+	var statusError *StatusError
+	response, err = LoadResponse(t.Context(), loader, "/docs/nowhere")
+	if !errors.As(err, &statusError) || statusError.StatusCode != http.StatusNotFound {
+		t.Fatal("expected status error, got:", err)
+	}
+	if string(response.Content) != "<!DOCTYPE html><title>not found</title>" {
+		t.Fatal("custom 404 page was not served:", string(response.Content))
+	}
+	if _, _, err = loader.Load(t.Context(), "/elsewhere/index.html"); !errors.As(err, &statusError) {
+		t.Fatal("locations outside of the base path must not be found, got:", err)
+	}
+	if _, _, err = loader.Load(t.Context(), "https://example.org/"); !errors.Is(err, Skip) {
+		t.Fatal("expected other hosts to be skipped, got:", err)
+	}
+}
@@ -3,6 +3,7 @@ package pageseo
 import (
 	"errors"
 	"net/url"
+	"slices"
 	"strings"
 	"testing"
 
@@ -16,12 +17,10 @@ func NewStyleSheetNodeTester() NodeTester {
 type styleSheet struct{}
 
 func (s styleSheet) Match(t testing.TB, node *html.Node) bool {
-	if node.Type != html.ElementNode || node.Data != "style" {
-		for _, attr := range node.Attr {
-			return attr.Key == "rel" && attr.Val == "stylesheet"
-		}
+	if node.Type != html.ElementNode || node.Data != "link" {
+		return false
 	}
-	return false
+	return slices.Contains(getLinkRelations(node), "stylesheet")
 }
 
 func (s styleSheet) ListResourcesForPreloading(origin *url.URL, node *html.Node) (URLs []string) {
@@ -53,16 +52,21 @@ func (s styleSheet) TestNode(t testing.TB, origin *url.URL, node *html.Node, loa
 		t.Error("missing <link[href]> attribute")
 		return
 	}
-	styleSheet, contentType, err := loader.Load(
-		t.Context(),
-		joinRelativePath(origin, href),
-	)
+	location := joinRelativePath(origin, href)
+	validateActiveContent(t, origin, "style sheet", location)
+	response, err := LoadResponse(t.Context(), loader, location)
 	if err != nil {
 		if errors.Is(err, Skip) {
 			return
 		}
 		t.Errorf("unable to load style sheet %q: %v", href, err)
 	}
+	var styleSheet []byte
+	var contentType string
+	if response != nil {
+		styleSheet, contentType = response.Content, response.ContentType
+		validateCompression(t, location, response)
+	}
 
 	switch contentType {
 	case "":
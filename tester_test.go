@@ -0,0 +1,127 @@
+package pageseo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// recordingTB collects the reports of a [NodeTester]
+// instead of failing the test.
+type recordingTB struct {
+	testing.TB
+	Errors   []string
+	Logs     []string
+	cleanups []func()
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Error(args ...any) {
+	r.Errors = append(r.Errors, strings.TrimSpace(fmt.Sprintln(args...)))
+}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Log(args ...any) {
+	r.Logs = append(r.Logs, strings.TrimSpace(fmt.Sprintln(args...)))
+}
+
+func (r *recordingTB) Logf(format string, args ...any) {
+	r.Logs = append(r.Logs, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+// HasError returns true if an error contains the fragment.
+func (r *recordingTB) HasError(fragment string) bool {
+	return slices.ContainsFunc(r.Errors, func(e string) bool {
+		return strings.Contains(e, fragment)
+	})
+}
+
+// HasLog returns true if a log line contains the fragment.
+func (r *recordingTB) HasLog(fragment string) bool {
+	return slices.ContainsFunc(r.Logs, func(l string) bool {
+		return strings.Contains(l, fragment)
+	})
+}
+
+// testNodes runs the tester against every matching node of
+// the document, the way [PageTester] does, and returns the
+// collected reports.
+func testNodes(t *testing.T, tester NodeTester, origin, document string, loader Loader) *recordingTB {
+	t.Helper()
+	tree, err := html.Parse(bytes.NewReader([]byte(document)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, err := url.Parse(origin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loader == nil {
+		loader = skipAllLoadingSingleton
+	}
+	recorder := &recordingTB{TB: t}
+	if tester.Match(recorder, tree) {
+		tester.TestNode(recorder, base, tree, loader)
+	}
+	for node := range tree.Descendants() {
+		if tester.Match(recorder, node) {
+			tester.TestNode(recorder, base, node, loader)
+		}
+	}
+	for i := len(recorder.cleanups) - 1; i >= 0; i-- {
+		recorder.cleanups[i]()
+	}
+	return recorder
+}
+
+// mapLoader serves the resources by location
+// and skips the others.
+type mapLoader map[string]Resource
+
+func (m mapLoader) Load(_ context.Context, URL string) ([]byte, string, error) {
+	r, ok := m[URL]
+	if !ok {
+		return nil, "", Skip
+	}
+	return r.Content, r.ContentType, r.Error
+}
+
+// responseMapLoader serves complete responses by location,
+// including their headers, and skips the others.
+type responseMapLoader map[string]*Response
+
+func (r responseMapLoader) Load(ctx context.Context, URL string) ([]byte, string, error) {
+	response, err := r.LoadResponse(ctx, URL)
+	if err != nil {
+		return nil, "", err
+	}
+	return response.Content, response.ContentType, nil
+}
+
+func (r responseMapLoader) LoadResponse(_ context.Context, URL string) (*Response, error) {
+	response, ok := r[URL]
+	if !ok {
+		return nil, Skip
+	}
+	if response.URL == "" {
+		response.URL = URL
+	}
+	if response.FinalURL == "" {
+		response.FinalURL = URL
+	}
+	return response, nil
+}
@@ -0,0 +1,64 @@
+package pageseo
+
+import (
+	"context"
+	"net/http"
+)
+
+// Validators identify a stored version of a resource.
+// Loaders that speak HTTP use them to make conditional
+// requests, which return HTTP 304 Not Modified without
+// a body when the resource has not changed.
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// IsZero returns true if there is nothing to validate against.
+func (v Validators) IsZero() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+type validatorsContextKey struct{}
+
+type locationValidators struct {
+	URL        string
+	Validators Validators
+}
+
+// WithValidators makes the load of the location within the
+// context conditional. Loads of other locations, like the
+// robots.txt file fetched on the way, remain unconditional.
+func WithValidators(ctx context.Context, URL string, v Validators) context.Context {
+	if v.IsZero() {
+		return ctx
+	}
+	return context.WithValue(ctx, validatorsContextKey{}, locationValidators{
+		URL:        URL,
+		Validators: v,
+	})
+}
+
+// ValidatorsFromContext returns the validators set
+// for the location with [WithValidators].
+func ValidatorsFromContext(ctx context.Context, URL string) (Validators, bool) {
+	v, ok := ctx.Value(validatorsContextKey{}).(locationValidators)
+	if !ok || v.URL != URL {
+		return Validators{}, false
+	}
+	return v.Validators, true
+}
+
+// ValidatorsFromHeader reads the validators of a response.
+func ValidatorsFromHeader(header http.Header) Validators {
+	return Validators{
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+	}
+}
+
+// IsNotModified returns true if the resource did not change
+// since the version identified by the request validators.
+func (r *Response) IsNotModified() bool {
+	return r != nil && r.StatusCode == http.StatusNotModified
+}
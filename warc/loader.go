@@ -0,0 +1,210 @@
+package warc
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/dkotik/pageseo"
+)
+
+// ErrNotArchived is returned by [FailOnMiss] for
+// locations that are missing from the archive.
+var ErrNotArchived = errors.New("location is not archived")
+
+// FailOnMiss is a fallback [pageseo.Loader] that
+// rejects every location with [ErrNotArchived].
+var FailOnMiss pageseo.Loader = failOnMiss{}
+
+type failOnMiss struct{}
+
+func (failOnMiss) Load(_ context.Context, URL string) ([]byte, string, error) {
+	return nil, "", fmt.Errorf("%w: <%s>", ErrNotArchived, URL)
+}
+
+// Open reads the WARC file, see [New].
+func Open(path string, fallback pageseo.Loader) (pageseo.Loader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open WARC file: %w", err)
+	}
+	defer f.Close()
+	return New(f, fallback)
+}
+
+// New replays the response records of a WARC stream, which
+// may be compressed with gzip. Locations missing from the
+// archive are passed to the fallback, which may be
+// [FailOnMiss] or another loader. A nil fallback returns
+// [pageseo.Skip] for them.
+func New(r io.Reader, fallback pageseo.Loader) (pageseo.Loader, error) {
+	buffered := bufio.NewReader(r)
+	if magic, _ := buffered.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		decompressed, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decompress WARC file: %w", err)
+		}
+		defer decompressed.Close()
+		r = decompressed
+	} else {
+		r = buffered
+	}
+
+	responses := make(map[string]*pageseo.Response)
+	records := NewReader(r)
+	for {
+		record, err := records.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if record.Type() != TypeResponse || !strings.HasPrefix(record.Header.Get("Content-Type"), "application/http") {
+			continue // DNS, metadata, and other records
+		}
+		location := record.TargetURI()
+		response, err := decode(location, record.Block)
+		if err != nil {
+			return nil, err
+		}
+		response.Truncated = record.Header.Get("WARC-Truncated") != ""
+		responses[normalize(location)] = response // later records win
+	}
+	return &loader{
+		Responses: responses,
+		Fallback:  fallback,
+	}, nil
+}
+
+func normalize(location string) string {
+	location, _, _ = strings.Cut(location, "#")
+	return location
+}
+
+func decode(location string, block []byte) (*pageseo.Response, error) {
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(block)), nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse archived response of <%s>: %w", location, err)
+	}
+	response := &pageseo.Response{
+		URL:        location,
+		FinalURL:   location,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+	}
+	// other archivers keep the content as transferred
+	body, encoding, err := pageseo.DecodeContent(resp.Header, resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decompress archived response of <%s>: %w", location, err)
+	}
+	response.ContentEncoding = encoding
+	response.Content, err = io.ReadAll(body)
+	if err = errors.Join(err, body.Close()); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) { // truncated by the crawler
+		return nil, fmt.Errorf("unable to read archived response of <%s>: %w", location, err)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		if response.ContentType, _, err = mime.ParseMediaType(contentType); err != nil {
+			return nil, fmt.Errorf("unable to parse archived content type %q: %w", contentType, err)
+		}
+	}
+	return response, nil
+}
+
+type loader struct {
+	Responses map[string]*pageseo.Response
+	Fallback  pageseo.Loader
+}
+
+func (l *loader) Load(ctx context.Context, URL string) ([]byte, string, error) {
+	response, err := l.LoadResponse(ctx, URL)
+	if response == nil {
+		return nil, "", err
+	}
+	return response.Content, response.ContentType, err
+}
+
+// maximumRedirects matches the limit of [http.Client].
+const maximumRedirects = 10
+
+// LoadResponse follows archived redirects and
+// returns a copy of the final archived response.
+func (l *loader) LoadResponse(ctx context.Context, URL string) (*pageseo.Response, error) {
+	archived, ok := l.Responses[normalize(URL)]
+	if !ok {
+		if l.Fallback == nil {
+			return nil, pageseo.Skip
+		}
+		return pageseo.LoadResponse(ctx, l.Fallback, URL)
+	}
+
+	var redirects []pageseo.Redirect
+	for archived.StatusCode >= 300 && archived.StatusCode < 400 {
+		location := archived.Header.Get("Location")
+		if location == "" {
+			break
+		}
+		to := resolve(archived.FinalURL, location)
+		redirects = append(redirects, pageseo.Redirect{
+			From:       archived.FinalURL,
+			To:         to,
+			StatusCode: archived.StatusCode,
+		})
+		if len(redirects) > maximumRedirects {
+			return nil, fmt.Errorf("stopped after %d archived redirects from <%s>", maximumRedirects, URL)
+		}
+		next, ok := l.Responses[normalize(to)]
+		if !ok {
+			break // the crawler did not follow
+		}
+		archived = next
+	}
+
+	response := *archived
+	response.URL = URL
+	response.Redirects = redirects
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return &response, &pageseo.StatusError{
+			StatusCode: response.StatusCode,
+			Header:     response.Header,
+		}
+	}
+	return &response, nil
+}
+
+func (l *loader) TraceRedirects(URL string) []pageseo.Redirect {
+	response, _ := l.LoadResponse(context.Background(), URL)
+	if response == nil {
+		return nil
+	}
+	return response.Redirects
+}
+
+// RecordPageLink passes the link to the fallback loader.
+func (l *loader) RecordPageLink(ctx context.Context, link pageseo.PageLink) error {
+	if l.Fallback == nil {
+		return nil
+	}
+	return pageseo.RecordPageLink(ctx, l.Fallback, link)
+}
+
+func resolve(base, location string) string {
+	b, err := url.Parse(base)
+	if err != nil {
+		return location
+	}
+	resolved, err := b.Parse(location)
+	if err != nil {
+		return location
+	}
+	return resolved.String()
+}
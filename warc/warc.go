@@ -0,0 +1,138 @@
+/*
+Package warc archives the resources loaded during a crawl
+into Web ARChive files, ISO 28500, and replays them later
+through a [pageseo.Loader].
+*/
+package warc
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// Version is written into the header of every record.
+const Version = "WARC/1.1"
+
+// Record types used by this package.
+const (
+	TypeInfo     = "warcinfo"
+	TypeRequest  = "request"
+	TypeResponse = "response"
+)
+
+// Record is a single WARC record.
+type Record struct {
+	Header textproto.MIMEHeader
+	Block  []byte
+}
+
+// Type returns the <WARC-Type> header.
+func (r Record) Type() string {
+	return r.Header.Get("WARC-Type")
+}
+
+// TargetURI returns the <WARC-Target-URI> header, which some
+// writers wrap into angle brackets.
+func (r Record) TargetURI() string {
+	return strings.Trim(r.Header.Get("WARC-Target-URI"), "<>")
+}
+
+// WriteTo writes the record with the <Content-Length>
+// header computed from the block.
+func (r Record) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	b.WriteString(Version + "\r\n")
+	for _, key := range [...]string{
+		"WARC-Type", "WARC-Record-ID", "WARC-Date", "WARC-Target-URI",
+		"WARC-Concurrent-To", "WARC-Payload-Digest", "WARC-Block-Digest",
+		"WARC-Truncated",
+		"Content-Type",
+	} {
+		for _, value := range r.Header.Values(key) {
+			b.WriteString(key + ": " + value + "\r\n")
+		}
+	}
+	b.WriteString("Content-Length: " + strconv.Itoa(len(r.Block)) + "\r\n\r\n")
+
+	n, err := io.WriteString(w, b.String())
+	total := int64(n)
+	if err != nil {
+		return total, err
+	}
+	n, err = w.Write(r.Block)
+	total += int64(n)
+	if err != nil {
+		return total, err
+	}
+	n, err = io.WriteString(w, "\r\n\r\n")
+	return total + int64(n), err
+}
+
+// Reader reads the records of an uncompressed WARC stream.
+// Compressed files are read through [gzip.Reader], which
+// joins the per-record members.
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader reads records from the stream.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Next returns the following record or [io.EOF]
+// at the end of the stream.
+func (r *Reader) Next() (*Record, error) {
+	var version string
+	for version == "" { // skip blank lines between records
+		line, err := r.r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && strings.TrimSpace(line) == "" {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("unable to read WARC record: %w", err)
+		}
+		version = strings.TrimSpace(line)
+	}
+	if !strings.HasPrefix(version, "WARC/1.") {
+		return nil, fmt.Errorf("unsupported WARC version %q", version)
+	}
+	header, err := textproto.NewReader(r.r).ReadMIMEHeader()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read WARC record header: %w", err)
+	}
+	length, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid WARC record <Content-Length> %q", header.Get("Content-Length"))
+	}
+	block := make([]byte, length)
+	if _, err = io.ReadFull(r.r, block); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("unable to read WARC record block: %w", err)
+	}
+	return &Record{Header: header, Block: block}, nil
+}
+
+func newRecordID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // variant 10
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// digest formats the SHA-1 digest the way most WARC tools do.
+func digest(data []byte) string {
+	sum := sha1.Sum(data)
+	return "sha1:" + base32.StdEncoding.EncodeToString(sum[:])
+}
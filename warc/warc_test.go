@@ -0,0 +1,131 @@
+package warc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/dkotik/pageseo"
+)
+
+func TestRoundTrip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+		case "/new":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("X-Robots-Tag", "noarchive")
+			_, _ = w.Write([]byte("<!DOCTYPE html><html><body>new</body></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	for _, name := range []string{"crawl.warc", "crawl.warc.gz"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			writer, err := Create(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			recording := writer.WrapLoader(pageseo.NewHTTPClient(server.Client(), nil))
+			if _, _, err = recording.Load(t.Context(), server.URL+"/old"); err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err = recording.Load(t.Context(), server.URL+"/missing"); err == nil {
+				t.Fatal("expected status error")
+			}
+			if err = errors.Join(writer.Err(), writer.Close()); err != nil {
+				t.Fatal(err)
+			}
+
+			replay, err := Open(path, FailOnMiss)
+			if err != nil {
+				t.Fatal(err)
+			}
+			response, err := pageseo.LoadResponse(t.Context(), replay, server.URL+"/old")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.FinalURL != server.URL+"/new" || response.ContentType != "text/html" {
+				t.Fatal("unexpected response:", response.FinalURL, response.ContentType)
+			}
+			if string(response.Content) != "<!DOCTYPE html><html><body>new</body></html>" {
+				t.Fatal("unexpected content:", string(response.Content))
+			}
+			if len(response.Redirects) != 1 || response.Redirects[0].StatusCode != http.StatusMovedPermanently {
+				t.Fatal("archived redirect was not followed:", response.Redirects)
+			}
+			if response.Header.Get("X-Robots-Tag") != "noarchive" {
+				t.Fatal("headers were not archived:", response.Header)
+			}
+
+			var statusError *pageseo.StatusError
+			if _, _, err = replay.Load(t.Context(), server.URL+"/missing"); !errors.As(err, &statusError) || statusError.StatusCode != http.StatusNotFound {
+				t.Fatal("expected archived status error, got:", err)
+			}
+			if _, _, err = replay.Load(t.Context(), server.URL+"/unknown"); !errors.Is(err, ErrNotArchived) {
+				t.Fatal("expected miss error, got:", err)
+			}
+		})
+	}
+}
+
+func TestReader(t *testing.T) {
+	stream := "WARC/1.0\r\n" +
+		"WARC-Type: response\r\n" +
+		"WARC-Target-URI: <https://example.com/>\r\n" +
+		"Content-Type: application/http; msgtype=response\r\n" +
+		"Content-Length: 56\r\n\r\n" +
+		"HTTP/1.1 200 OK\r\nContent-Type: text/plain\r\n\r\nhello world\r\n\r\n" +
+		"WARC/1.0\r\n" +
+		"WARC-Type: metadata\r\n" +
+		"Content-Length: 0\r\n\r\n\r\n\r\n"
+	replay, err := New(bytes.NewReader([]byte(stream)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, ct, err := replay.Load(t.Context(), "https://example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct != "text/plain" || string(data) != "hello world" {
+		t.Fatal("unexpected content:", ct, string(data))
+	}
+	if _, _, err = replay.Load(t.Context(), "https://example.com/other"); !errors.Is(err, pageseo.Skip) {
+		t.Fatal("expected skip, got:", err)
+	}
+}
+
+func TestEncodedRecord(t *testing.T) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, _ = gz.Write([]byte("hello world"))
+	_ = gz.Close()
+
+	block := "HTTP/1.1 200 OK\r\nContent-Type: text/plain\r\nContent-Encoding: X-Gzip\r\n\r\n" + compressed.String()
+	stream := "WARC/1.0\r\n" +
+		"WARC-Type: response\r\n" +
+		"WARC-Target-URI: <https://example.com/>\r\n" +
+		"Content-Type: application/http; msgtype=response\r\n" +
+		"Content-Length: " + strconv.Itoa(len(block)) + "\r\n\r\n" +
+		block + "\r\n\r\n"
+	replay, err := New(bytes.NewReader([]byte(stream)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := pageseo.LoadResponse(t.Context(), replay, "https://example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(response.Content) != "hello world" || response.ContentEncoding != "gzip" {
+		t.Fatalf("archived content was not decoded: %q %q", response.Content, response.ContentEncoding)
+	}
+}
@@ -0,0 +1,244 @@
+package warc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dkotik/pageseo"
+)
+
+// Writer appends request and response records to a WARC
+// stream. It is a [pageseo.Middleware] that records every
+// load performed by the wrapped [pageseo.Loader].
+type Writer struct {
+	mu       sync.Mutex
+	w        io.Writer
+	closer   io.Closer
+	compress bool
+	err      error
+}
+
+// Create writes a new WARC file. Paths ending with ".gz"
+// compress every record into a separate gzip member, as
+// archiving tools expect.
+func Create(path string) (*Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create WARC file: %w", err)
+	}
+	w, err := NewWriter(f, strings.HasSuffix(path, ".gz"))
+	if err != nil {
+		return nil, errors.Join(err, f.Close())
+	}
+	w.closer = f
+	return w, nil
+}
+
+// NewWriter begins the WARC stream with a warcinfo record.
+func NewWriter(w io.Writer, compress bool) (*Writer, error) {
+	if w == nil {
+		panic("nil writer")
+	}
+	writer := &Writer{w: w, compress: compress}
+	header := textproto.MIMEHeader{}
+	header.Set("WARC-Type", TypeInfo)
+	header.Set("WARC-Record-ID", newRecordID())
+	header.Set("WARC-Date", time.Now().UTC().Format(time.RFC3339))
+	header.Set("Content-Type", "application/warc-fields")
+	if err := writer.write(Record{
+		Header: header,
+		Block:  []byte("software: pageseo\r\nformat: WARC File Format 1.1\r\n"),
+	}); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// Close closes the file opened by [Create].
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closer == nil {
+		return nil
+	}
+	err := w.closer.Close()
+	w.closer = nil
+	return err
+}
+
+// Err returns the first error that stopped the recording.
+// Loads are not interrupted by recording failures.
+func (w *Writer) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+func (w *Writer) write(records ...Record) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	for _, record := range records {
+		if w.compress {
+			member := gzip.NewWriter(w.w)
+			if _, w.err = record.WriteTo(member); w.err == nil {
+				w.err = member.Close()
+			}
+		} else {
+			_, w.err = record.WriteTo(w.w)
+		}
+		if w.err != nil {
+			w.err = fmt.Errorf("unable to write WARC record: %w", w.err)
+			return w.err
+		}
+	}
+	return nil
+}
+
+// WriteResponse records the response together with a
+// request record. Every redirect is recorded as a separate
+// exchange, so that replay can follow the same chain.
+func (w *Writer) WriteResponse(response *pageseo.Response) error {
+	if response == nil {
+		return errors.New("nil response")
+	}
+	date := time.Now().UTC()
+	if response.Duration > 0 {
+		date = date.Add(-response.Duration)
+	}
+	var records []Record
+	for _, redirect := range response.Redirects {
+		header := http.Header{}
+		header.Set("Location", redirect.To)
+		records = append(records, exchange(date, redirect.From, redirect.StatusCode, header, nil)...)
+	}
+	location := response.FinalURL
+	if location == "" {
+		location = response.URL
+	}
+	header := response.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	if response.ContentType != "" && header.Get("Content-Type") == "" {
+		header.Set("Content-Type", response.ContentType)
+	}
+	status := response.StatusCode
+	if status == 0 {
+		status = http.StatusOK // loaders that do not speak HTTP
+	}
+	final := exchange(date, location, status, header, response.Content)
+	if response.Truncated {
+		final[1].Header.Set("WARC-Truncated", "length")
+	}
+	return w.write(append(records, final...)...)
+}
+
+// exchange creates the request and the response records.
+// The content is stored decoded, so the headers that
+// describe the transfer are replaced.
+func exchange(date time.Time, location string, status int, header http.Header, content []byte) []Record {
+	header = header.Clone()
+	header.Del("Content-Encoding")
+	header.Del("Transfer-Encoding")
+	header.Set("Content-Length", fmt.Sprint(len(content)))
+
+	var response bytes.Buffer
+	fmt.Fprintf(&response, "HTTP/1.1 %d %s\r\n", status, http.StatusText(status))
+	_ = header.Write(&response)
+	response.WriteString("\r\n")
+	response.Write(content)
+
+	var request bytes.Buffer
+	path, host := location, ""
+	if parsed, err := url.Parse(location); err == nil {
+		path, host = parsed.RequestURI(), parsed.Host
+	}
+	fmt.Fprintf(&request, "GET %s HTTP/1.1\r\nHost: %s\r\n\r\n", path, host)
+
+	responseID := newRecordID()
+	responseHeader := textproto.MIMEHeader{}
+	responseHeader.Set("WARC-Type", TypeResponse)
+	responseHeader.Set("WARC-Record-ID", responseID)
+	responseHeader.Set("WARC-Date", date.Format(time.RFC3339))
+	responseHeader.Set("WARC-Target-URI", location)
+	responseHeader.Set("WARC-Payload-Digest", digest(content))
+	responseHeader.Set("WARC-Block-Digest", digest(response.Bytes()))
+	responseHeader.Set("Content-Type", "application/http;msgtype=response")
+
+	requestHeader := textproto.MIMEHeader{}
+	requestHeader.Set("WARC-Type", TypeRequest)
+	requestHeader.Set("WARC-Record-ID", newRecordID())
+	requestHeader.Set("WARC-Date", date.Format(time.RFC3339))
+	requestHeader.Set("WARC-Target-URI", location)
+	requestHeader.Set("WARC-Concurrent-To", responseID)
+	requestHeader.Set("WARC-Block-Digest", digest(request.Bytes()))
+	requestHeader.Set("Content-Type", "application/http;msgtype=request")
+
+	return []Record{
+		{Header: requestHeader, Block: request.Bytes()},
+		{Header: responseHeader, Block: response.Bytes()},
+	}
+}
+
+// WrapLoader records every response of the loader, including
+// HTTP error responses. Failed writes are kept in [Writer.Err]
+// and do not fail the loads.
+func (w *Writer) WrapLoader(l pageseo.Loader) pageseo.Loader {
+	if l == nil {
+		panic("nil loader")
+	}
+	return recorder{Loader: l, Writer: w}
+}
+
+type recorder struct {
+	Loader pageseo.Loader
+	Writer *Writer
+}
+
+func (r recorder) Load(ctx context.Context, URL string) ([]byte, string, error) {
+	response, err := r.LoadResponse(ctx, URL)
+	if response == nil {
+		return nil, "", err
+	}
+	return response.Content, response.ContentType, err
+}
+
+func (r recorder) LoadResponse(ctx context.Context, URL string) (*pageseo.Response, error) {
+	response, err := pageseo.LoadResponse(ctx, r.Loader, URL)
+	if response == nil || errors.Is(err, pageseo.Skip) || response.IsNotModified() {
+		return response, err
+	}
+	var statusError *pageseo.StatusError
+	if err == nil || errors.As(err, &statusError) {
+		_ = r.Writer.WriteResponse(response)
+	}
+	return response, err
+}
+
+func (r recorder) TraceRedirects(URL string) []pageseo.Redirect {
+	return pageseo.TraceRedirects(r.Loader, URL)
+}
+
+func (r recorder) RecordPageLink(ctx context.Context, link pageseo.PageLink) error {
+	return pageseo.RecordPageLink(ctx, r.Loader, link)
+}
+
+func (r recorder) SetCrawlDelay(host string, delay time.Duration) {
+	if delayer, ok := r.Loader.(pageseo.CrawlDelayer); ok {
+		delayer.SetCrawlDelay(host, delay)
+	}
+}